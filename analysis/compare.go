@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// minSignificanceSamples is the smallest number of spans in each set for which a change is reported
+// as significant
+const minSignificanceSamples = 5
+
+// LatencySummary describes the distribution of durations of spans with the same name
+type LatencySummary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+}
+
+// Comparison describes how the latency distribution of spans with a given name changed between two
+// sets of recorded traces
+type Comparison struct {
+	Name   string
+	Before LatencySummary
+	After  LatencySummary
+
+	// Change is the relative change in median latency, so 0.25 means the median became 25% slower
+	Change float64
+
+	// Z is the normal approximation of the Mann-Whitney U statistic. Positive values indicate that
+	// spans in the after set tend to be slower.
+	Z float64
+
+	// Significant is a hint that the change is unlikely to be due to chance, set when |Z| exceeds 1.96
+	// and both sets contain enough spans
+	Significant bool
+}
+
+// Compare reports, for each span name present in either set, how the latency distribution changed
+// from the before set to the after set. Results are ordered by span name.
+func Compare(before, after []sdktrace.ReadOnlySpan) []Comparison {
+	b := durationsByName(before)
+	a := durationsByName(after)
+
+	names := map[string]bool{}
+	for name := range b {
+		names[name] = true
+	}
+	for name := range a {
+		names[name] = true
+	}
+
+	cs := make([]Comparison, 0, len(names))
+	for name := range names {
+		c := Comparison{
+			Name:   name,
+			Before: summarize(b[name]),
+			After:  summarize(a[name]),
+		}
+		if c.Before.P50 > 0 && c.After.Count > 0 {
+			c.Change = float64(c.After.P50-c.Before.P50) / float64(c.Before.P50)
+		}
+		if len(b[name]) > 0 && len(a[name]) > 0 {
+			c.Z = mannWhitneyZ(b[name], a[name])
+			c.Significant = math.Abs(c.Z) > 1.96 && len(b[name]) >= minSignificanceSamples && len(a[name]) >= minSignificanceSamples
+		}
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return cs
+}
+
+// WriteComparisons writes comparisons as an aligned text table, marking significant changes
+func WriteComparisons(w io.Writer, cs []Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tBEFORE N\tBEFORE P50\tBEFORE P99\tAFTER N\tAFTER P50\tAFTER P99\tCHANGE\t")
+	for _, c := range cs {
+		mark := ""
+		if c.Significant {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%s\t%s\t%+.1f%%\t%s\n", c.Name,
+			c.Before.Count, c.Before.P50, c.Before.P99,
+			c.After.Count, c.After.P50, c.After.P99,
+			c.Change*100, mark)
+	}
+	return tw.Flush()
+}
+
+func durationsByName(spans []sdktrace.ReadOnlySpan) map[string][]time.Duration {
+	ds := map[string][]time.Duration{}
+	for _, s := range spans {
+		ds[s.Name()] = append(ds[s.Name()], s.EndTime().Sub(s.StartTime()))
+	}
+	for _, d := range ds {
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	}
+	return ds
+}
+
+// summarize expects durations to be sorted
+func summarize(ds []time.Duration) LatencySummary {
+	if len(ds) == 0 {
+		return LatencySummary{}
+	}
+	return LatencySummary{
+		Count: len(ds),
+		P50:   percentile(ds, 0.50),
+		P90:   percentile(ds, 0.90),
+		P99:   percentile(ds, 0.99),
+	}
+}
+
+func percentile(ds []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(ds)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return ds[i]
+}
+
+// mannWhitneyZ returns the normal approximation z-score of the Mann-Whitney U statistic for the after
+// sample, using average ranks for ties
+func mannWhitneyZ(before, after []time.Duration) float64 {
+	type sample struct {
+		d     time.Duration
+		after bool
+	}
+	all := make([]sample, 0, len(before)+len(after))
+	for _, d := range before {
+		all = append(all, sample{d: d})
+	}
+	for _, d := range after {
+		all = append(all, sample{d: d, after: true})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].d < all[j].d })
+
+	var rankSum float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].d == all[i].d {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // average of ranks i+1 to j
+		for k := i; k < j; k++ {
+			if all[k].after {
+				rankSum += rank
+			}
+		}
+		i = j
+	}
+
+	n1, n2 := float64(len(after)), float64(len(before))
+	u := rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	sd := math.Sqrt(n1 * n2 * (n1 + n2 + 1) / 12)
+	if sd == 0 {
+		return 0
+	}
+	return (u - mean) / sd
+}
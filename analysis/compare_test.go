@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spansWithDurations(name string, ds ...time.Duration) []sdktrace.ReadOnlySpan {
+	start := time.Unix(0, 0)
+	spans := make([]sdktrace.ReadOnlySpan, len(ds))
+	for i, d := range ds {
+		spans[i] = tracetest.SpanStub{Name: name, StartTime: start, EndTime: start.Add(d)}.Snapshot()
+	}
+	return spans
+}
+
+func millis(from, to int) []time.Duration {
+	var ds []time.Duration
+	for i := from; i < to; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	return ds
+}
+
+func TestCompare(t *testing.T) {
+	before := spansWithDurations("get", millis(10, 20)...)
+	after := append(spansWithDurations("get", millis(20, 30)...), spansWithDurations("put", millis(1, 3)...)...)
+
+	cs := Compare(before, after)
+	if len(cs) != 2 || cs[0].Name != "get" || cs[1].Name != "put" {
+		t.Fatalf("got comparisons %+v, want get and put in order", cs)
+	}
+
+	get := cs[0]
+	if get.Before.Count != 10 || get.Before.P50 != 14*time.Millisecond || get.After.P50 != 24*time.Millisecond {
+		t.Errorf("get summaries: got before %+v, after %+v", get.Before, get.After)
+	}
+	if want := 10.0 / 14.0; math.Abs(get.Change-want) > 1e-9 {
+		t.Errorf("get change: got %v, want %v", get.Change, want)
+	}
+	// every after span is slower, so U is n1*n2 and z is (100-50)/sqrt(100*21/12)
+	if want := 50 / math.Sqrt(175); math.Abs(get.Z-want) > 1e-9 {
+		t.Errorf("get z: got %v, want %v", get.Z, want)
+	}
+	if !get.Significant {
+		t.Error("get: want a significant change")
+	}
+
+	put := cs[1]
+	if put.Before.Count != 0 || put.After.Count != 2 || put.Change != 0 || put.Significant {
+		t.Errorf("put, only in the after set: got %+v", put)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteComparisons(&buf, cs); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(strings.TrimSpace(lines[1]), "*") || strings.HasSuffix(strings.TrimSpace(lines[2]), "*") {
+		t.Errorf("got table:\n%s\nwant only get marked significant", buf.String())
+	}
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	spans := spansWithDurations("get", millis(10, 20)...)
+	cs := Compare(spans, spans)
+	if len(cs) != 1 || cs[0].Z != 0 || cs[0].Change != 0 || cs[0].Significant {
+		t.Errorf("identical sets: got %+v, want no change", cs)
+	}
+}
@@ -0,0 +1,144 @@
+// Package analysis provides utilities for summarizing spans recorded from IPFS nodes.
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OriginFunc returns the origin that a local root span and the rest of its trace should be attributed
+// to, such as a gateway path prefix, an API token or a peer. An empty result means the span is not
+// attributed to any origin.
+type OriginFunc func(s sdktrace.ReadOnlySpan) string
+
+// AttributeOrigin returns an OriginFunc that attributes spans to the value of an attribute
+func AttributeOrigin(key attribute.Key) OriginFunc {
+	return func(s sdktrace.ReadOnlySpan) string {
+		for _, kv := range s.Attributes() {
+			if kv.Key == key {
+				return kv.Value.Emit()
+			}
+		}
+		return ""
+	}
+}
+
+// PathPrefixOrigin returns an OriginFunc that attributes spans to the first segments of the path held
+// in an attribute, so that /ipfs/bafy.../a/b is attributed to /ipfs/bafy... when segments is 2. Spans
+// are attributed to the full path if segments is not positive.
+func PathPrefixOrigin(key attribute.Key, segments int) OriginFunc {
+	value := AttributeOrigin(key)
+	if segments <= 0 {
+		return value
+	}
+	return func(s sdktrace.ReadOnlySpan) string {
+		p := value(s)
+		if p == "" {
+			return ""
+		}
+		parts := strings.SplitN(strings.TrimPrefix(p, "/"), "/", segments+1)
+		if len(parts) > segments {
+			parts = parts[:segments]
+		}
+		return "/" + strings.Join(parts, "/")
+	}
+}
+
+// LoadEntry is the load attributed to a single origin
+type LoadEntry struct {
+	Origin   string        `json:"origin"`
+	Traces   int           `json:"traces"`
+	Duration time.Duration `json:"duration_ns"`
+	Bytes    int64         `json:"bytes"`
+}
+
+// LoadReport is the load attributed to each origin over a time window
+type LoadReport struct {
+	Start   time.Time   `json:"start"`
+	End     time.Time   `json:"end"`
+	Entries []LoadEntry `json:"entries"`
+}
+
+// AttributeLoad attributes the wall-clock time of local root spans that started within the window
+// to their origins, together with the sum of the byte counts recorded in bytesKey by any span in the
+// same traces. Entries are ordered by descending duration. A zero start or end leaves that side of the
+// window open.
+func AttributeLoad(spans []sdktrace.ReadOnlySpan, origin OriginFunc, bytesKey attribute.Key, start, end time.Time) *LoadReport {
+	inWindow := func(s sdktrace.ReadOnlySpan) bool {
+		t := s.StartTime()
+		return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end))
+	}
+
+	entries := map[string]*LoadEntry{}
+	traceOrigins := map[trace.TraceID]string{}
+	for _, s := range spans {
+		if s.Parent().IsValid() && !s.Parent().IsRemote() {
+			continue
+		}
+		if !inWindow(s) {
+			continue
+		}
+		o := origin(s)
+		if o == "" {
+			continue
+		}
+		e, ok := entries[o]
+		if !ok {
+			e = &LoadEntry{Origin: o}
+			entries[o] = e
+		}
+		e.Traces++
+		e.Duration += s.EndTime().Sub(s.StartTime())
+		traceOrigins[s.SpanContext().TraceID()] = o
+	}
+
+	for _, s := range spans {
+		o, ok := traceOrigins[s.SpanContext().TraceID()]
+		if !ok {
+			continue
+		}
+		for _, kv := range s.Attributes() {
+			if kv.Key == bytesKey {
+				entries[o].Bytes += kv.Value.AsInt64()
+			}
+		}
+	}
+
+	r := &LoadReport{Start: start, End: end, Entries: make([]LoadEntry, 0, len(entries))}
+	for _, e := range entries {
+		r.Entries = append(r.Entries, *e)
+	}
+	sort.Slice(r.Entries, func(i, j int) bool {
+		if r.Entries[i].Duration != r.Entries[j].Duration {
+			return r.Entries[i].Duration > r.Entries[j].Duration
+		}
+		return r.Entries[i].Origin < r.Entries[j].Origin
+	})
+	return r
+}
+
+// WriteText writes the report as an aligned text table
+func (r *LoadReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORIGIN\tTRACES\tDURATION\tBYTES")
+	for _, e := range r.Entries {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\n", e.Origin, e.Traces, e.Duration, e.Bytes)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as JSON
+func (r *LoadReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPathPrefixOrigin(t *testing.T) {
+	const path = "/ipfs/bafy/a/b"
+	testCases := []struct {
+		segments int
+		want     string
+	}{
+		{segments: -1, want: path},
+		{segments: 0, want: path},
+		{segments: 1, want: "/ipfs"},
+		{segments: 2, want: "/ipfs/bafy"},
+		{segments: 4, want: path},
+		{segments: 10, want: path},
+	}
+
+	s := tracetest.SpanStub{Attributes: []attribute.KeyValue{attribute.String("path", path)}}.Snapshot()
+	for _, tc := range testCases {
+		if got := PathPrefixOrigin("path", tc.segments)(s); got != tc.want {
+			t.Errorf("segments %d: got %q, want %q", tc.segments, got, tc.want)
+		}
+	}
+
+	empty := tracetest.SpanStub{}.Snapshot()
+	if got := PathPrefixOrigin("path", 2)(empty); got != "" {
+		t.Errorf("span without the attribute: got %q, want no origin", got)
+	}
+}
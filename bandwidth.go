@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BandwidthTotals returns the total number of bytes received and sent by the node, such as the
+// TotalIn and TotalOut fields of the stats returned by a libp2p bandwidth counter's GetBandwidthTotals
+type BandwidthTotals func() (in int64, out int64)
+
+// WithBandwidth wraps a span so that the number of bytes received and sent by the node while the span
+// was running is recorded on it when it ends, correlating latency with the bytes actually moved. The
+// totals are node wide so concurrent work is included in the deltas, making this most useful for
+// selected spans such as root gateway spans. The returned context holds the wrapped span.
+func WithBandwidth(ctx context.Context, span trace.Span, totals BandwidthTotals) (context.Context, trace.Span) {
+	if !span.IsRecording() {
+		return ctx, span
+	}
+
+	bs := &bandwidthSpan{Span: span, totals: totals}
+	bs.in, bs.out = totals()
+	wrapRegistered(span, bs)
+	return trace.ContextWithSpan(ctx, bs), bs
+}
+
+type bandwidthSpan struct {
+	trace.Span
+	totals  BandwidthTotals
+	in, out int64
+	once    sync.Once
+}
+
+func (s *bandwidthSpan) unwrap() trace.Span { return s.Span }
+
+func (s *bandwidthSpan) End(opts ...trace.SpanEndOption) {
+	s.once.Do(func() {
+		in, out := s.totals()
+		s.Span.SetAttributes(
+			BytesAttribute("bandwidth.in", in-s.in),
+			BytesAttribute("bandwidth.out", out-s.out),
+		)
+	})
+	s.Span.End(opts...)
+}
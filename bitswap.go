@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// DefaultWantlistSnapshotSize is the number of cids included in a want-list snapshot by default
+const DefaultWantlistSnapshotSize = 10
+
+// AddWantlistSnapshotEvent records an event on the span holding the number of outstanding wants and
+// up to max of their cids
+func AddWantlistSnapshotEvent(span trace.Span, wants []cid.Cid, max int) {
+	if span.IsRecording() {
+		span.AddEvent("wantlist snapshot", trace.WithAttributes(
+			attribute.Int("wants.count", len(wants)),
+			attribute.String("wants", cidListString(wants, max)),
+		))
+	}
+}
+
+// SnapshotWantlist records a want-list snapshot event on the span every interval, using the wants
+// function to obtain the session's current want-list, until the returned stop function is called or
+// the context is cancelled. Snapshots in verbose traces include every cid.
+func SnapshotWantlist(ctx context.Context, span trace.Span, interval time.Duration, max int, wants func() []cid.Cid) (stop func()) {
+	if !span.IsRecording() {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		for {
+			tick, stop := after(interval)
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-tick:
+				ws := wants()
+				if Verbose(ctx) {
+					AddWantlistSnapshotEvent(span, ws, len(ws))
+				} else {
+					AddWantlistSnapshotEvent(span, ws, max)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// MessageDirection describes whether a bitswap message was sent or received
+type MessageDirection string
+
+const (
+	MessageSent     MessageDirection = "sent"
+	MessageReceived MessageDirection = "received"
+)
+
+// BitswapMessageSummary holds the counts of the entries in a bitswap message
+type BitswapMessageSummary struct {
+	Wants     int  // want-block and want-have entries
+	Cancels   int  // cancel entries
+	Blocks    int  // blocks carried by the message
+	Haves     int  // have block presences
+	DontHaves int  // dont-have block presences
+	Size      int  // size of the encoded message in bytes
+	Full      bool // whether the message replaces the whole want-list
+}
+
+// AddBitswapMessageEvent records a single event on the span summarizing a bitswap message sent or
+// received, giving message level visibility without the cost of recording each entry
+func AddBitswapMessageEvent(span trace.Span, dir MessageDirection, msg BitswapMessageSummary) {
+	if span.IsRecording() {
+		span.AddEvent("bitswap message", trace.WithAttributes(
+			attribute.String("bitswap.direction", string(dir)),
+			attribute.Int("bitswap.wants.count", msg.Wants),
+			attribute.Int("bitswap.cancels.count", msg.Cancels),
+			attribute.Int("bitswap.blocks.count", msg.Blocks),
+			attribute.Int("bitswap.haves.count", msg.Haves),
+			attribute.Int("bitswap.dont_haves.count", msg.DontHaves),
+			BytesAttribute("bitswap.message.size", int64(msg.Size)),
+			attribute.Bool("bitswap.wantlist.full", msg.Full),
+		))
+	}
+}
+
+// SessionCounters accumulates the responses received by a bitswap session so they can be recorded on
+// the session span when the session ends. Duplicate block rates are a key measure of bitswap
+// efficiency. It is safe for concurrent use.
+type SessionCounters struct {
+	haves      int64
+	dontHaves  int64
+	blocks     int64
+	duplicates int64
+}
+
+// AddHave counts a have response
+func (c *SessionCounters) AddHave() { atomic.AddInt64(&c.haves, 1) }
+
+// AddDontHave counts a dont-have response
+func (c *SessionCounters) AddDontHave() { atomic.AddInt64(&c.dontHaves, 1) }
+
+// AddBlock counts a block received that was wanted by the session
+func (c *SessionCounters) AddBlock() { atomic.AddInt64(&c.blocks, 1) }
+
+// AddDuplicate counts a block received that the session had already received
+func (c *SessionCounters) AddDuplicate() { atomic.AddInt64(&c.duplicates, 1) }
+
+// Attributes creates span attributes with standard names for the counts accumulated so far, the
+// fraction of presence responses that were haves and the fraction of received blocks that were
+// duplicates
+func (c *SessionCounters) Attributes() []attribute.KeyValue {
+	haves := atomic.LoadInt64(&c.haves)
+	dontHaves := atomic.LoadInt64(&c.dontHaves)
+	blocks := atomic.LoadInt64(&c.blocks)
+	duplicates := atomic.LoadInt64(&c.duplicates)
+
+	var haveRatio, duplicateRatio float64
+	if haves+dontHaves > 0 {
+		haveRatio = float64(haves) / float64(haves+dontHaves)
+	}
+	if blocks+duplicates > 0 {
+		duplicateRatio = float64(duplicates) / float64(blocks+duplicates)
+	}
+
+	return []attribute.KeyValue{
+		attribute.Int64("session.haves.count", haves),
+		attribute.Int64("session.dont_haves.count", dontHaves),
+		attribute.Int64("session.blocks.count", blocks),
+		attribute.Int64("session.duplicates.count", duplicates),
+		attribute.Float64("session.have.ratio", haveRatio),
+		attribute.Float64("session.duplicate.ratio", duplicateRatio),
+	}
+}
+
+// SetAttributes records the counts on a session span, typically just before the span ends
+func (c *SessionCounters) SetAttributes(span trace.Span) {
+	if span.IsRecording() {
+		span.SetAttributes(c.Attributes()...)
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+func TestAddWantlistSnapshotEventClampsMax(t *testing.T) {
+	rec := withRecorder(t)
+	wants := []cid.Cid{
+		cid.NewCidV1(cid.Raw, mustMultihash(t, "a")),
+		cid.NewCidV1(cid.Raw, mustMultihash(t, "b")),
+	}
+
+	testCases := []struct {
+		max  int
+		want string
+	}{
+		{max: -1, want: "2 cids"},
+		{max: 0, want: "2 cids"},
+		{max: 1, want: wants[0].String() + " and 1 more"},
+		{max: 5, want: wants[0].String() + "," + wants[1].String()},
+	}
+
+	for _, tc := range testCases {
+		_, span := Span(context.Background(), "Test", "Snapshot")
+		AddWantlistSnapshotEvent(span, wants, tc.max)
+		span.End()
+
+		ended := rec.Ended()
+		events := ended[len(ended)-1].Events()
+		if len(events) != 1 {
+			t.Fatalf("max %d: got %d events, want 1", tc.max, len(events))
+		}
+		if got := attributeMap(events[0].Attributes)["wants"]; got != tc.want {
+			t.Errorf("max %d: got wants %q, want %q", tc.max, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// AddBlockExistsEvent records an event on the span noting that a block write was skipped because
+// the block was already present in the blockstore
+func AddBlockExistsEvent(span trace.Span, c cid.Cid) {
+	if span.IsRecording() {
+		span.AddEvent("block exists", trace.WithAttributes(CidAttribute(c)))
+	}
+}
+
+// AddBlockPinnedEvent records an event on the span noting that garbage collection skipped a block
+// because it is pinned
+func AddBlockPinnedEvent(span trace.Span, c cid.Cid) {
+	if span.IsRecording() {
+		span.AddEvent("block pinned", trace.WithAttributes(CidAttribute(c)))
+	}
+}
+
+// AddDuplicateBlockEvent records an event on the span noting that a block was received that was
+// already present locally, wasting the bandwidth used to fetch it. The size is the size of the block
+// in bytes and the peer is the id of the peer that sent it.
+func AddDuplicateBlockEvent(span trace.Span, c cid.Cid, size int, peer string) {
+	if span.IsRecording() {
+		span.AddEvent("duplicate block", trace.WithAttributes(
+			CidAttribute(c),
+			BlockSizeAttribute(size),
+			attribute.String("peer", peer),
+		))
+	}
+}
+
+// SpanForBloomRebuild starts a span covering the rebuild of a caching blockstore's bloom filter.
+// The capacity is the configured size of the filter. Callers should record the number of keys
+// added using KeyCountAttribute before ending the span.
+func SpanForBloomRebuild(ctx context.Context, componentName string, capacity int) (context.Context, trace.Span) {
+	return SpanWithIntAttribute(ctx, componentName, "BloomRebuild", "bloom.capacity", capacity)
+}
+
+// SpanForCacheWarmup starts a span covering the warming of a caching blockstore's ARC cache.
+// The capacity is the configured number of entries in the cache. Callers should record the number
+// of keys loaded using KeyCountAttribute before ending the span.
+func SpanForCacheWarmup(ctx context.Context, componentName string, capacity int) (context.Context, trace.Span) {
+	return SpanWithIntAttribute(ctx, componentName, "CacheWarmup", "cache.capacity", capacity)
+}
+
+// KeyCountAttribute creates a span attribute with a standard name for representing the number of
+// keys processed by a maintenance operation
+func KeyCountAttribute(n int) attribute.KeyValue {
+	return attribute.Int("keys.count", n)
+}
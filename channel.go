@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceChannel wraps a result channel so that the span covers the lifetime of the channel rather
+// than the call that returned it. The span records the latency of the first result, the number of
+// items received and the reason the channel finished. The span is ended when the source channel is
+// closed or the context is cancelled, so callers must not end it themselves.
+func TraceChannel[T any](ctx context.Context, span trace.Span, ch <-chan T) <-chan T {
+	if !span.IsRecording() {
+		span.End()
+		return ch
+	}
+
+	out := make(chan T)
+	start := now()
+	go func() {
+		defer close(out)
+
+		count := 0
+		reason := "closed"
+		defer func() {
+			span.SetAttributes(
+				attribute.Int("channel.items.count", count),
+				attribute.String("channel.close_reason", reason),
+			)
+			span.End()
+		}()
+
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				if count == 0 {
+					span.SetAttributes(DurationAttribute("channel.first_result", since(start)))
+				}
+				count++
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					reason = ctx.Err().Error()
+					return
+				}
+			case <-ctx.Done():
+				reason = ctx.Err().Error()
+				return
+			}
+		}
+	}()
+	return out
+}
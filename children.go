@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChildRecorder records the timing of each iteration of a loop as an event on a single span. It is
+// intended for loops over blocks or links where a span per item is too expensive. A ChildRecorder is
+// not safe for concurrent use.
+type ChildRecorder struct {
+	span  trace.Span
+	index int
+	start time.Time
+}
+
+// StartChildren starts a span covering a loop of n iterations and returns a recorder for the timings
+// of the individual iterations. Each iteration should be bracketed by calls to Begin and Done and
+// the recorder ended with End once the loop completes.
+func StartChildren(ctx context.Context, componentName string, spanName string, n int) (context.Context, *ChildRecorder) {
+	ctx, span := SpanWithIntAttribute(ctx, componentName, spanName, "iterations.expected_count", n)
+	return ctx, &ChildRecorder{span: span}
+}
+
+// Span returns the span the recorder records events on
+func (r *ChildRecorder) Span() trace.Span {
+	return r.span
+}
+
+// Begin marks the start of the next iteration
+func (r *ChildRecorder) Begin() {
+	if r.span.IsRecording() {
+		r.start = now()
+	}
+}
+
+// Done marks the end of the current iteration, recording an event with the iteration's index,
+// duration and any additional attributes
+func (r *ChildRecorder) Done(attrs ...attribute.KeyValue) {
+	if r.span.IsRecording() {
+		eventAttrs := make([]attribute.KeyValue, 0, len(attrs)+2)
+		eventAttrs = append(eventAttrs,
+			attribute.Int("iteration.index", r.index),
+			attribute.Int64("iteration.duration_us", since(r.start).Microseconds()),
+		)
+		eventAttrs = append(eventAttrs, attrs...)
+		r.span.AddEvent("iteration", trace.WithAttributes(eventAttrs...))
+	}
+	r.index++
+}
+
+// End records the number of completed iterations and ends the span
+func (r *ChildRecorder) End(opts ...trace.SpanEndOption) {
+	if r.span.IsRecording() {
+		r.span.SetAttributes(attribute.Int("iterations.count", r.index))
+	}
+	r.span.End(opts...)
+}
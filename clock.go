@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Clock is a source of time for the helpers in this package. It may be replaced using SetClock so
+// that tests can control time and produce traces with stable timestamps and durations.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+type clockHolder struct {
+	clock Clock
+}
+
+var activeClock atomic.Value
+
+// SetClock replaces the clock used by this package. When a clock is set, spans started by this
+// package are given explicit start, end and event timestamps taken from it. Passing nil restores
+// the system clock.
+func SetClock(c Clock) {
+	activeClock.Store(clockHolder{clock: c})
+}
+
+// customClock returns the clock set using SetClock or nil if the system clock is in use
+func customClock() Clock {
+	h, _ := activeClock.Load().(clockHolder)
+	return h.clock
+}
+
+func now() time.Time {
+	if c := customClock(); c != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// after returns a channel that receives the time once d has elapsed, along with a function that
+// releases the underlying timer. Callers that stop waiting before the channel fires must call it, since
+// a timer is otherwise held until it fires.
+func after(d time.Duration) (<-chan time.Time, func()) {
+	if c := customClock(); c != nil {
+		return c.After(d), func() {}
+	}
+	t := time.NewTimer(d)
+	return t.C, func() { t.Stop() }
+}
+
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// withClock wraps the span so that its end and event timestamps are taken from the clock
+func withClock(ctx context.Context, span trace.Span, c Clock) (context.Context, trace.Span) {
+	cs := &clockSpan{Span: span, clock: c}
+	return trace.ContextWithSpan(ctx, cs), cs
+}
+
+type clockSpan struct {
+	trace.Span
+	clock Clock
+}
+
+func (s *clockSpan) End(opts ...trace.SpanEndOption) {
+	s.Span.End(append([]trace.SpanEndOption{trace.WithTimestamp(s.clock.Now())}, opts...)...)
+}
+
+func (s *clockSpan) AddEvent(name string, opts ...trace.EventOption) {
+	s.Span.AddEvent(name, append([]trace.EventOption{trace.WithTimestamp(s.clock.Now())}, opts...)...)
+}
+
+// ClockOffsetAttribute creates a resource attribute with a standard name for representing the offset of
+// the node's clock from a reference clock, such as the offset reported by NTP. A positive offset means
+// the node's clock is ahead. Recording the offset lets trace viewers explain or correct the timings of
+// spans from different nodes in the same trace.
+func ClockOffsetAttribute(offset time.Duration) attribute.KeyValue {
+	return DurationAttribute("ipfs.clock.offset", offset)
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CoalescedAttribute creates a span attribute with a standard name for marking a span whose work was
+// coalesced into a concurrent request for the same content, such as by a singleflight group
+func CoalescedAttribute() attribute.KeyValue {
+	return attribute.Bool("coalesced", true)
+}
+
+// SpanForCoalesced starts a span for a request that is waiting on a concurrent leader request for the
+// same content instead of doing the work itself. The span is linked to the span of the leader and is
+// marked with CoalescedAttribute so that the unexpectedly short or long duration of a coalesced
+// request can be explained by following the link. An invalid leader span context is not linked.
+func SpanForCoalesced(ctx context.Context, componentName string, spanName string, leader trace.SpanContext, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	// limit the capacity so that appending never writes into the array of a slice passed by the caller
+	opts = append(opts[:len(opts):len(opts)], trace.WithAttributes(CoalescedAttribute()))
+	if leader.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: leader}))
+	}
+	return Span(ctx, componentName, spanName, opts...)
+}
+
+// SetCoalescedFollowers records on a leader span the number of other requests that were coalesced
+// into it
+func SetCoalescedFollowers(span trace.Span, n int) {
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("coalesced.followers.count", n))
+	}
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanForCoalescedDoesNotModifyCallerOptions(t *testing.T) {
+	rec := withRecorder(t)
+	_, leader := Span(context.Background(), "Test", "Leader")
+	defer leader.End()
+
+	// a slice with spare capacity, as built by a caller that appends its own options
+	opts := make([]trace.SpanStartOption, 1, 4)
+	opts[0] = trace.WithAttributes(attribute.String("key", "value"))
+	spare := opts[:cap(opts)]
+
+	_, span := SpanForCoalesced(context.Background(), "Test", "Follower", leader.SpanContext(), opts...)
+	span.End()
+
+	for i := len(opts); i < len(spare); i++ {
+		if spare[i] != nil {
+			t.Errorf("option %d of the caller's array was overwritten", i)
+		}
+	}
+
+	ended := rec.Ended()
+	s := ended[len(ended)-1]
+	attrs := attributeMap(s.Attributes())
+	if attrs["key"] != "value" || attrs["coalesced"] != "true" {
+		t.Errorf("got attributes %v, want key and coalesced", attrs)
+	}
+	if len(s.Links()) != 1 || s.Links()[0].SpanContext.SpanID() != leader.SpanContext().SpanID() {
+		t.Errorf("got links %v, want a link to the leader", s.Links())
+	}
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// disabledComponents holds a map of the names of components whose spans are suppressed
+var disabledComponents atomic.Value
+
+// disabledSpan is the non-recording span returned for suppressed components
+var disabledSpan = trace.SpanFromContext(context.Background())
+
+// SetComponentsEnabled configures which components are instrumented. Components mapped to false have
+// their spans suppressed by the helpers in this package, which return the parent context unchanged
+// and a span that records nothing. Components missing from the map are enabled. The map is copied so
+// later changes to it have no effect.
+func SetComponentsEnabled(components map[string]bool) {
+	disabled := make(map[string]bool)
+	for name, enabled := range components {
+		if !enabled {
+			disabled[name] = true
+		}
+	}
+	disabledComponents.Store(disabled)
+}
+
+// ComponentEnabled reports whether spans are started for the named component
+func ComponentEnabled(componentName string) bool {
+	disabled, _ := disabledComponents.Load().(map[string]bool)
+	return len(disabled) == 0 || !disabled[componentName]
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+type commonAttributesKey struct{}
+
+// WithCommonAttributes returns a context carrying attributes that will be added to every span started
+// by this package using the returned context or any context derived from it. Attributes are added to
+// any already present in the context.
+func WithCommonAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	existing := CommonAttributesFromContext(ctx)
+	combined := make([]attribute.KeyValue, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, commonAttributesKey{}, combined)
+}
+
+// CommonAttributesFromContext returns the attributes added to the context by WithCommonAttributes
+func CommonAttributesFromContext(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(commonAttributesKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+type rootCidKey struct{}
+
+// WithRootCid returns a context declaring the root cid of the content being served by the current
+// request. Every span started by this package using the returned context, or any context derived
+// from it, carries the cid as an attribute so that low-level spans can be grouped by the content
+// they serve.
+func WithRootCid(ctx context.Context, c cid.Cid) context.Context {
+	if !c.Defined() {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, rootCidKey{}, c)
+	return WithCommonAttributes(ctx, RootCidAttribute(c))
+}
+
+// RootCidFromContext returns the root cid declared using WithRootCid. The boolean result is false if
+// no root cid has been declared.
+func RootCidFromContext(ctx context.Context) (cid.Cid, bool) {
+	c, ok := ctx.Value(rootCidKey{}).(cid.Cid)
+	return c, ok
+}
+
+// RootCidAttribute creates a span attribute with a standard name for representing the root cid of
+// the content being served
+func RootCidAttribute(c cid.Cid) attribute.KeyValue {
+	return attribute.String("root_cid", cidString(c))
+}
+
+type operationNameKey struct{}
+
+// WithOperationName returns a context naming the logical user operation, such as "cat" or "ls", that
+// the next call through an instrumented API performs. CoreAPI option types cannot be extended so the
+// name is carried by the context instead.
+func WithOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+// OperationNameFromContext returns the operation name added to the context by WithOperationName
+func OperationNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(operationNameKey{}).(string)
+	return name, ok && name != ""
+}
+
+// SpanForOperation starts a span for a call to an API method. If the context holds an operation name
+// the span is named after the operation rather than the method and the method is recorded as an
+// attribute. The operation name is consumed so that nested calls are named after their methods, but it
+// is added to the common attributes of the returned context so every nested span records it.
+func SpanForOperation(ctx context.Context, componentName string, methodName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	op, ok := OperationNameFromContext(ctx)
+	if !ok {
+		return Span(ctx, componentName, methodName, opts...)
+	}
+	ctx = context.WithValue(ctx, operationNameKey{}, "")
+	ctx = WithCommonAttributes(ctx, attribute.String("operation", op))
+	opts = append(opts[:len(opts):len(opts)], trace.WithAttributes(attribute.String("method", methodName)))
+	return Span(ctx, componentName, op, opts...)
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanForOperation(t *testing.T) {
+	rec := withRecorder(t)
+
+	opts := make([]trace.SpanStartOption, 1, 4)
+	opts[0] = trace.WithAttributes(attribute.String("key", "value"))
+	spare := opts[:cap(opts)]
+
+	ctx := WithOperationName(context.Background(), "cat")
+	ctx, span := SpanForOperation(ctx, "CoreAPI", "Get", opts...)
+	_, nested := SpanForOperation(ctx, "CoreAPI", "Resolve")
+	nested.End()
+	span.End()
+
+	for i := len(opts); i < len(spare); i++ {
+		if spare[i] != nil {
+			t.Errorf("option %d of the caller's array was overwritten", i)
+		}
+	}
+
+	ended := rec.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d spans, want 2", len(ended))
+	}
+
+	nestedSpan, opSpan := ended[0], ended[1]
+	if opSpan.Name() != "CoreAPI.cat" {
+		t.Errorf("operation span name: got %q, want CoreAPI.cat", opSpan.Name())
+	}
+	attrs := attributeMap(opSpan.Attributes())
+	if attrs["method"] != "Get" || attrs["key"] != "value" || attrs["operation"] != "cat" {
+		t.Errorf("operation span attributes: got %v", attrs)
+	}
+
+	if nestedSpan.Name() != "CoreAPI.Resolve" {
+		t.Errorf("nested span name: got %q, want CoreAPI.Resolve", nestedSpan.Name())
+	}
+	if got := attributeMap(nestedSpan.Attributes())["operation"]; got != "cat" {
+		t.Errorf("nested span operation: got %q, want cat", got)
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// HandleCrash is intended to be deferred at the start of main and of long lived goroutines. If the
+// goroutine panics, HandleCrash calls CrashSpans with the panic value and then re-raises the panic.
+func HandleCrash() {
+	if r := recover(); r != nil {
+		CrashSpans(fmt.Sprint(r))
+		panic(r)
+	}
+}
+
+// CrashSpans force-ends every open span tracked by the span registry, marking each with a crashed
+// attribute and an error status holding the reason, and then flushes the global tracer provider within
+// DefaultShutdownTimeout. It is intended for panic hooks and fatal signal handlers that are about to
+// terminate the process, so that post-mortem traces include the work that was in flight. Spans are only
+// tracked once the registry has been enabled using EnableSpanRegistry.
+func CrashSpans(reason string) {
+	for _, rs := range registry.all() {
+		s := rs.outermost()
+		s.SetAttributes(attribute.Bool("crashed", true))
+		s.SetStatus(codes.Error, "crashed: "+reason)
+		s.End()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	if f, ok := otel.GetTracerProvider().(interface{ ForceFlush(context.Context) error }); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			otel.Handle(err)
+		}
+	}
+}
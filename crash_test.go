@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCrashSpansEndsWrappedSpans(t *testing.T) {
+	withRecorder(t)
+	EnableSpanRegistry(true)
+	defer EnableSpanRegistry(false)
+
+	ctx, span := Span(context.Background(), "Test", "Fetch")
+	_, hspan := Heartbeat(ctx, time.Hour, time.Hour, nil)
+	hs := hspan.(*heartbeatSpan)
+	if hs.Span != span {
+		t.Fatal("heartbeat does not wrap the span")
+	}
+
+	CrashSpans("test")
+	select {
+	case <-hs.done:
+	default:
+		t.Error("heartbeat not stopped when CrashSpans ended the span")
+	}
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DAGLeafSizeBuckets are the upper bounds in bytes of the leaf size histogram buckets recorded by
+// DAGShape. Leaves larger than the last bound are counted in a final overflow bucket.
+var DAGLeafSizeBuckets = []int64{1 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// DAGShape summarizes the shape of a DAG as it is traversed so that differences between fetches of
+// flat and deep DAGs can be seen when comparing traces. It is safe for concurrent use by the
+// goroutines of a parallel traversal.
+type DAGShape struct {
+	mu        sync.Mutex
+	depth     int
+	nodes     int
+	links     int
+	maxFanout int
+	leaves    int
+	buckets   []int64
+}
+
+// AddNode records a node visited at the given depth, where the root has a depth of zero, with the
+// number of links it has and the size of its data in bytes. Nodes without links are counted as leaves.
+func (d *DAGShape) AddNode(depth int, links int, size int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nodes++
+	if depth > d.depth {
+		d.depth = depth
+	}
+	if links > 0 {
+		d.links += links
+		if links > d.maxFanout {
+			d.maxFanout = links
+		}
+		return
+	}
+
+	d.leaves++
+	if d.buckets == nil {
+		d.buckets = make([]int64, len(DAGLeafSizeBuckets)+1)
+	}
+	i := 0
+	for i < len(DAGLeafSizeBuckets) && int64(size) > DAGLeafSizeBuckets[i] {
+		i++
+	}
+	d.buckets[i]++
+}
+
+// Attributes creates span attributes with standard names describing the shape recorded so far: the
+// maximum depth, the number of nodes and leaves, the maximum and mean fanout of nodes with links, and
+// a histogram of leaf sizes using the bounds in DAGLeafSizeBuckets
+func (d *DAGShape) Attributes() []attribute.KeyValue {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var meanFanout float64
+	if branches := d.nodes - d.leaves; branches > 0 {
+		meanFanout = float64(d.links) / float64(branches)
+	}
+	buckets := d.buckets
+	if buckets == nil {
+		buckets = make([]int64, len(DAGLeafSizeBuckets)+1)
+	}
+
+	return []attribute.KeyValue{
+		attribute.Int("dag.depth", d.depth),
+		attribute.Int("dag.nodes.count", d.nodes),
+		attribute.Int("dag.leaves.count", d.leaves),
+		attribute.Int("dag.fanout.max_count", d.maxFanout),
+		attribute.Float64("dag.fanout.mean_count", meanFanout),
+		attribute.Int64Slice("dag.leaf.bucket_bytes", DAGLeafSizeBuckets),
+		attribute.Int64Slice("dag.leaf.bucket_count", append([]int64(nil), buckets...)),
+	}
+}
+
+// SetAttributes records the shape on a traversal span, typically just before the span ends
+func (d *DAGShape) SetAttributes(span trace.Span) {
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(d.Attributes()...)
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Outcomes of a denylist check recorded by EndDenylistCheck
+const (
+	DenylistAllowed = "allowed"
+	DenylistBlocked = "blocked"
+	DenylistError   = "error"
+)
+
+// SpanForDenylistCheck starts a span covering the lookup of a cid or path in a content denylist, such
+// as those used by nopfs. The subject is recorded using DenylistSubjectAttribute since the outcome, and
+// so whether the subject is blocked content, is not known when the span starts. Callers should end the
+// span using EndDenylistCheck.
+func SpanForDenylistCheck(ctx context.Context, componentName string, subject string) (context.Context, trace.Span) {
+	return Span(ctx, componentName, "DenylistCheck", trace.WithAttributes(DenylistSubjectAttribute(subject)))
+}
+
+// EndDenylistCheck records the outcome of a denylist check, which should be one of the Denylist
+// constants, and ends the span. The rule is the denylist entry that matched, if any. It is recorded
+// as a short hash so that operators can audit which rules are hit and group checks by rule without the
+// trace revealing the blocked content. An error is recorded using EndSpan.
+func EndDenylistCheck(span trace.Span, componentName string, outcome string, rule string, err error) {
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("denylist.outcome", outcome))
+		if rule != "" {
+			span.SetAttributes(DenylistRuleAttribute(rule))
+		}
+	}
+	EndSpan(span, componentName, err)
+}
+
+// DenylistRuleAttribute creates a span attribute with a standard name for representing a denylist
+// rule by a hash of its text
+func DenylistRuleAttribute(rule string) attribute.KeyValue {
+	return attribute.String("denylist.rule", shortHash(rule))
+}
+
+// DenylistSubjectAttribute creates a span attribute with a standard name for representing the cid or
+// path checked against a denylist by a hash of its text. Checks of the same subject can be grouped, and
+// an operator can find the checks of a known subject by hashing it, without traces naming blocked
+// content.
+func DenylistSubjectAttribute(subject string) attribute.KeyValue {
+	return attribute.String("denylist.subject", shortHash(subject))
+}
+
+// shortHash returns the hex encoding of the first eight bytes of the sha256 digest of s
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDenylistCheckDoesNotRecordSubjectInClear(t *testing.T) {
+	rec := withRecorder(t)
+	const subject = "/ipfs/bafkqaaa/blocked.txt"
+	const rule = "//ipfs/bafkqaaa"
+
+	_, span := SpanForDenylistCheck(context.Background(), "Gateway", subject)
+	EndDenylistCheck(span, "Gateway", DenylistBlocked, rule, nil)
+
+	ended := rec.Ended()
+	s := ended[len(ended)-1]
+	attrs := attributeMap(s.Attributes())
+	for k, v := range attrs {
+		if strings.Contains(v, "bafkqaaa") {
+			t.Errorf("attribute %s records the blocked content in clear: %q", k, v)
+		}
+	}
+	if got, want := attrs["denylist.subject"], DenylistSubjectAttribute(subject).Value.AsString(); got != want {
+		t.Errorf("denylist.subject: got %q, want %q", got, want)
+	}
+	if got, want := attrs["denylist.rule"], DenylistRuleAttribute(rule).Value.AsString(); got != want {
+		t.Errorf("denylist.rule: got %q, want %q", got, want)
+	}
+	if attrs["denylist.outcome"] != DenylistBlocked {
+		t.Errorf("denylist.outcome: got %q, want %q", attrs["denylist.outcome"], DenylistBlocked)
+	}
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Crawl traces a crawl or refresh cycle of the accelerated DHT client. These cycles run in the
+// background for several minutes and significantly affect node performance.
+type Crawl struct {
+	span    trace.Span
+	start   time.Time
+	stop    chan struct{}
+	once    sync.Once
+	peers   int64
+	failed  int64
+	filled  int64
+	buckets int64
+}
+
+// StartCrawl starts a span covering a crawl of the DHT. While the crawl runs a "crawl progress" event
+// is recorded every interval holding the counts so far. End must be called when the crawl completes.
+func StartCrawl(ctx context.Context, componentName string, interval time.Duration) (context.Context, *Crawl) {
+	ctx, span := Span(ctx, componentName, "Crawl")
+	c := &Crawl{
+		span:  span,
+		start: now(),
+		stop:  make(chan struct{}),
+	}
+	if span.IsRecording() && interval > 0 {
+		go c.run(interval)
+	}
+	return ctx, c
+}
+
+// PeerContacted counts a peer queried by the crawl, noting whether the query failed
+func (c *Crawl) PeerContacted(failed bool) {
+	atomic.AddInt64(&c.peers, 1)
+	if failed {
+		atomic.AddInt64(&c.failed, 1)
+	}
+}
+
+// SetBucketCoverage records how many of the routing table's buckets hold at least one peer
+func (c *Crawl) SetBucketCoverage(filled int, buckets int) {
+	atomic.StoreInt64(&c.filled, int64(filled))
+	atomic.StoreInt64(&c.buckets, int64(buckets))
+}
+
+// End records the final counts and bucket coverage on the crawl span and ends it
+func (c *Crawl) End() {
+	c.once.Do(func() { close(c.stop) })
+	if c.span.IsRecording() {
+		c.span.SetAttributes(c.attributes()...)
+		c.span.SetAttributes(
+			attribute.Int64("dht.buckets.filled_count", atomic.LoadInt64(&c.filled)),
+			attribute.Int64("dht.buckets.count", atomic.LoadInt64(&c.buckets)),
+		)
+	}
+	c.span.End()
+}
+
+func (c *Crawl) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("dht.peers.contacted_count", atomic.LoadInt64(&c.peers)),
+		attribute.Int64("dht.peers.failed_count", atomic.LoadInt64(&c.failed)),
+	}
+}
+
+func (c *Crawl) run(interval time.Duration) {
+	for {
+		tick, stop := after(interval)
+		select {
+		case <-c.stop:
+			stop()
+			return
+		case <-tick:
+			c.span.AddEvent("crawl progress", trace.WithAttributes(
+				append(c.attributes(), DurationAttribute("elapsed", since(c.start)))...,
+			))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"net"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Resolver is the set of DNS lookups used by IPFS for DNSLink and DNS multiaddrs. It is satisfied by
+// *net.Resolver and by madns basic resolvers, including DNS over HTTPS clients.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// NewTracedResolver wraps a resolver so that each lookup is covered by a span recording the query
+// name, query type, resolver endpoint and number of answers. The endpoint describes where queries are
+// sent, such as the url of a DNS over HTTPS server or "system" for the operating system's resolver.
+func NewTracedResolver(r Resolver, endpoint string) Resolver {
+	return &tracedResolver{r: r, endpoint: endpoint}
+}
+
+type tracedResolver struct {
+	r        Resolver
+	endpoint string
+}
+
+func (t *tracedResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ctx, span := t.span(ctx, "LookupIPAddr", host, "IP")
+	defer span.End()
+
+	addrs, err := t.r.LookupIPAddr(ctx, host)
+	endLookup(span, len(addrs), err)
+	return addrs, err
+}
+
+func (t *tracedResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	ctx, span := t.span(ctx, "LookupTXT", name, "TXT")
+	defer span.End()
+
+	txts, err := t.r.LookupTXT(ctx, name)
+	endLookup(span, len(txts), err)
+	return txts, err
+}
+
+func (t *tracedResolver) span(ctx context.Context, spanName string, name string, queryType string) (context.Context, trace.Span) {
+	return Span(ctx, "DNS", spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("dns.name", name),
+			attribute.String("dns.type", queryType),
+			attribute.String("dns.resolver", t.endpoint),
+		),
+	)
+}
+
+func endLookup(span trace.Span, answers int, err error) {
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attribute.Int("dns.answers.count", answers))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
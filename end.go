@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CancelStatus is the status given by EndSpan to spans that end because their context was canceled
+type CancelStatus int
+
+const (
+	// CancelError ends canceled spans with an Error status, as for any other error. It is the default.
+	CancelError CancelStatus = iota
+
+	// CancelOk ends canceled spans with an Ok status
+	CancelOk
+
+	// CancelUnset ends canceled spans without setting a status
+	CancelUnset
+)
+
+// cancelStatuses holds a map of component names to the CancelStatus configured for them
+var cancelStatuses atomic.Value
+
+// SetCancelStatus configures, per component, how EndSpan and WithSpan treat spans that end with
+// context.Canceled. Gateways see many requests aborted by clients and may not want them counted as
+// errors, while cancellation of internal work usually is one. Spans of components missing from the
+// map use CancelError. Canceled spans are always given a canceled attribute so they can still be
+// found. The map is copied so later changes to it have no effect.
+func SetCancelStatus(statuses map[string]CancelStatus) {
+	m := make(map[string]CancelStatus, len(statuses))
+	for name, st := range statuses {
+		m[name] = st
+	}
+	cancelStatuses.Store(m)
+}
+
+func cancelStatus(componentName string) CancelStatus {
+	m, _ := cancelStatuses.Load().(map[string]CancelStatus)
+	return m[componentName]
+}
+
+// EndSpan records the outcome of an operation on a span started for the named component and ends it.
+// A non-nil error is recorded and sets an Error status, except that context.Canceled is treated as
+// configured using SetCancelStatus.
+func EndSpan(span trace.Span, componentName string, err error) {
+	if err != nil && span.IsRecording() {
+		if errors.Is(err, context.Canceled) {
+			span.SetAttributes(attribute.Bool("canceled", true))
+			switch cancelStatus(componentName) {
+			case CancelOk:
+				span.SetStatus(codes.Ok, "")
+			case CancelUnset:
+			default:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+		} else {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// WithSpan runs fn within a new span, passing it the context holding the span, and ends the span
+// using EndSpan with the error returned by fn
+func WithSpan(ctx context.Context, componentName string, spanName string, fn func(context.Context) error, opts ...trace.SpanStartOption) error {
+	ctx, span := Span(ctx, componentName, spanName, opts...)
+	err := fn(ctx)
+	EndSpan(span, componentName, err)
+	return err
+}
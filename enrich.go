@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Enricher adds attributes to every span started by this package, allowing plugins to attach
+// deployment specific details such as region, tenant or shard without changing the instrumentation
+type Enricher interface {
+	// OnStart returns attributes to add to a span as it is started. The context is the parent
+	// context passed to Span.
+	OnStart(ctx context.Context, componentName string, spanName string) []attribute.KeyValue
+
+	// OnEnd returns attributes to add to a span just before it is ended. The context is the one
+	// returned by Span.
+	OnEnd(ctx context.Context, componentName string, spanName string) []attribute.KeyValue
+}
+
+var (
+	enrichersMu sync.Mutex
+	enrichers   atomic.Value
+)
+
+// RegisterEnricher adds an enricher that will be called for every span subsequently started by this
+// package. Enrichers are called in the order they were registered, so when two enrichers return the
+// same attribute key the value from the one registered last is kept. A panic in an enricher is
+// recovered and reported to the OpenTelemetry error handler so that it cannot affect the caller.
+func RegisterEnricher(e Enricher) {
+	enrichersMu.Lock()
+	defer enrichersMu.Unlock()
+
+	existing := activeEnrichers()
+	es := make([]Enricher, 0, len(existing)+1)
+	es = append(es, existing...)
+	es = append(es, e)
+	enrichers.Store(es)
+}
+
+func activeEnrichers() []Enricher {
+	es, _ := enrichers.Load().([]Enricher)
+	return es
+}
+
+// enrichStart returns the start attributes from all enrichers
+func enrichStart(es []Enricher, ctx context.Context, componentName string, spanName string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, e := range es {
+		attrs = append(attrs, callEnricher(func() []attribute.KeyValue { return e.OnStart(ctx, componentName, spanName) })...)
+	}
+	return attrs
+}
+
+func callEnricher(f func() []attribute.KeyValue) (attrs []attribute.KeyValue) {
+	defer func() {
+		if r := recover(); r != nil {
+			otel.Handle(fmt.Errorf("tracing: span enricher panicked: %v", r))
+			attrs = nil
+		}
+	}()
+	return f()
+}
+
+// withEnrichers wraps the span so that the enrichers are called before it is ended
+func withEnrichers(ctx context.Context, span trace.Span, es []Enricher, componentName string, spanName string) (context.Context, trace.Span) {
+	s := &enrichedSpan{Span: span, enrichers: es, component: componentName, name: spanName}
+	ctx = trace.ContextWithSpan(ctx, s)
+	s.ctx = ctx
+	return ctx, s
+}
+
+type enrichedSpan struct {
+	trace.Span
+	ctx       context.Context
+	enrichers []Enricher
+	component string
+	name      string
+}
+
+func (s *enrichedSpan) End(opts ...trace.SpanEndOption) {
+	for _, e := range s.enrichers {
+		if attrs := callEnricher(func() []attribute.KeyValue { return e.OnEnd(s.ctx, s.component, s.name) }); len(attrs) > 0 {
+			s.SetAttributes(attrs...)
+		}
+	}
+	s.Span.End(opts...)
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// stringPath is a Path holding an arbitrary string, such as a path containing binary data
+type stringPath string
+
+func (p stringPath) String() string { return string(p) }
+
+func FuzzPathAttribute(f *testing.F) {
+	f.Add("/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/readme")
+	f.Add("/ipns/example.com/\xff\xfe")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, p string) {
+		kv := PathAttribute(stringPath(p))
+		if v := kv.Value.AsString(); !utf8.ValidString(v) {
+			t.Errorf("path attribute holds invalid UTF-8: %q", v)
+		}
+	})
+}
+
+func FuzzCidListAttribute(f *testing.F) {
+	f.Add([]byte{0x01, 0x55, 0x12, 0x20}, uint8(4))
+	f.Add(cid.NewCidV1(cid.Raw, []byte{0x00, 0x00}).Bytes(), uint8(8))
+	f.Add([]byte{}, uint8(1))
+	f.Fuzz(func(t *testing.T, data []byte, chunk uint8) {
+		// split the input into candidate cids, keeping malformed ones as cid.Undef
+		size := int(chunk)%64 + 1
+		var cs []cid.Cid
+		for len(data) > 0 {
+			n := size
+			if n > len(data) {
+				n = len(data)
+			}
+			c, _ := cid.Cast(data[:n])
+			cs = append(cs, c)
+			data = data[n:]
+		}
+
+		for _, kv := range CidListAttributes(cs) {
+			if v := kv.Value.Emit(); !utf8.ValidString(v) {
+				t.Errorf("attribute %s holds invalid UTF-8: %q", kv.Key, v)
+			}
+		}
+	})
+}
+
+func FuzzParseTraceStateEntry(f *testing.F) {
+	f.Add("o:gateway;s:keep")
+	f.Add("o:\xc3\x28;x:y")
+	f.Add(";;:")
+	f.Fuzz(func(t *testing.T, v string) {
+		e, err := ParseTraceStateEntry(v)
+		if err != nil {
+			return
+		}
+		if !utf8.ValidString(e.Origin) || !utf8.ValidString(e.SamplingHint) {
+			t.Errorf("parsed entry holds invalid UTF-8: %+v", e)
+		}
+		if s := e.String(); !utf8.ValidString(s) {
+			t.Errorf("entry renders invalid UTF-8: %q", s)
+		}
+	})
+}
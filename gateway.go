@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redirectPropagator is used to carry trace context in redirect urls. It is limited to W3C trace
+// context so that baggage is never exposed in urls.
+var redirectPropagator = propagation.TraceContext{}
+
+// InjectRedirect adds the trace context held in the context to the query of a redirect url, such as
+// the redirect from a path gateway to a subdomain gateway, so that the trace continues when the client
+// follows the redirect
+func InjectRedirect(ctx context.Context, u *url.URL) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	q := u.Query()
+	redirectPropagator.Inject(ctx, queryCarrier(q))
+	u.RawQuery = q.Encode()
+}
+
+// ExtractRedirect returns a context holding any trace context added to the request's url by
+// InjectRedirect. Requests that carry trace context headers are left to the usual header based
+// propagation and the context is returned unchanged.
+func ExtractRedirect(ctx context.Context, r *http.Request) context.Context {
+	for _, field := range redirectPropagator.Fields() {
+		if r.Header.Get(field) != "" {
+			return ctx
+		}
+	}
+	return redirectPropagator.Extract(ctx, queryCarrier(r.URL.Query()))
+}
+
+// ErrorPageTraceID returns the id of the trace held in the context for display on generated error
+// pages, linking a failure seen by a user to the backend trace. It returns an empty string if the
+// trace is not sampled since there would be no trace to find.
+func ErrorPageTraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// ConditionalRequestHandler wraps a gateway http handler so that the span held in the request context
+// records how conditional requests were handled: whether the request carried If-None-Match or
+// If-Modified-Since headers, whether the response etag matched, whether a 304 response was sent and
+// the Cache-Control header of the response. This makes the effectiveness of HTTP caching measurable
+// per trace. It should be installed inside the handler that starts the request span.
+func ConditionalRequestHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		inm := r.Header.Get("If-None-Match")
+		span.SetAttributes(
+			attribute.Bool("http.request.if_none_match", inm != ""),
+			attribute.Bool("http.request.if_modified_since", r.Header.Get("If-Modified-Since") != ""),
+		)
+
+		cw := &conditionalResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+
+		h := w.Header()
+		attrs := []attribute.KeyValue{
+			attribute.Bool("http.response.not_modified", cw.status == http.StatusNotModified),
+		}
+		if inm != "" {
+			attrs = append(attrs, attribute.Bool("http.response.etag_match", etagMatches(inm, h.Get("Etag"))))
+		}
+		if cc := h.Get("Cache-Control"); cc != "" {
+			attrs = append(attrs, attribute.String("http.response.cache_control", cc))
+		}
+		span.SetAttributes(attrs...)
+	})
+}
+
+type conditionalResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+var _ http.Flusher = (*conditionalResponseWriter)(nil)
+
+func (w *conditionalResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *conditionalResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *conditionalResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// etagMatches reports whether an etag matches any entry of an If-None-Match header using the weak
+// comparison required for that header
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// queryCarrier adapts url query values to a propagation.TextMapCarrier
+type queryCarrier url.Values
+
+var _ propagation.TextMapCarrier = queryCarrier(nil)
+
+func (q queryCarrier) Get(key string) string {
+	return url.Values(q).Get(key)
+}
+
+func (q queryCarrier) Set(key string, value string) {
+	url.Values(q).Set(key, value)
+}
+
+func (q queryCarrier) Keys() []string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	return keys
+}
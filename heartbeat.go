@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProgressFunc returns attributes describing the progress of a long running operation
+type ProgressFunc func() []attribute.KeyValue
+
+// Heartbeat wraps the span held in the context so that, once the span has been running for longer
+// than threshold, a "still running" event is recorded on it every interval. Each event carries the
+// elapsed time and any attributes returned by progress, which may be nil. The heartbeat stops when
+// the returned span is ended. Span returns the parent context unchanged for a disabled component, so
+// HeartbeatFor should be used with the span it returns to avoid adding a heartbeat to the parent.
+func Heartbeat(ctx context.Context, threshold time.Duration, interval time.Duration, progress ProgressFunc) (context.Context, trace.Span) {
+	return HeartbeatFor(ctx, trace.SpanFromContext(ctx), threshold, interval, progress)
+}
+
+// HeartbeatFor is like Heartbeat but wraps the given span rather than the one held in the context.
+// The returned context holds the wrapped span. No heartbeat is added to a span that is not recording,
+// such as the span returned for a disabled component.
+func HeartbeatFor(ctx context.Context, span trace.Span, threshold time.Duration, interval time.Duration, progress ProgressFunc) (context.Context, trace.Span) {
+	if !span.IsRecording() {
+		return ctx, span
+	}
+
+	hs := &heartbeatSpan{Span: span, done: make(chan struct{})}
+	wrapRegistered(span, hs)
+	go hs.run(now(), threshold, interval, progress)
+	return trace.ContextWithSpan(ctx, hs), hs
+}
+
+type heartbeatSpan struct {
+	trace.Span
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *heartbeatSpan) unwrap() trace.Span { return s.Span }
+
+func (s *heartbeatSpan) End(opts ...trace.SpanEndOption) {
+	s.once.Do(func() { close(s.done) })
+	s.Span.End(opts...)
+}
+
+func (s *heartbeatSpan) run(start time.Time, threshold time.Duration, interval time.Duration, progress ProgressFunc) {
+	wait := threshold
+	for {
+		tick, stop := after(wait)
+		select {
+		case <-s.done:
+			stop()
+			return
+		case <-tick:
+		}
+		// the wrapped span may have been ended directly, bypassing End
+		if !s.Span.IsRecording() {
+			return
+		}
+
+		attrs := []attribute.KeyValue{DurationAttribute("elapsed", since(start))}
+		if progress != nil {
+			attrs = append(attrs, progress()...)
+		}
+		s.AddEvent("still running", trace.WithAttributes(attrs...))
+		wait = interval
+	}
+}
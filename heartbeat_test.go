@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestHeartbeatForDisabledComponent(t *testing.T) {
+	withRecorder(t)
+	SetComponentsEnabled(map[string]bool{"Disabled": false})
+	defer SetComponentsEnabled(nil)
+
+	ctx, parent := Span(context.Background(), "Test", "Parent")
+	defer parent.End()
+
+	ctx, span := Span(ctx, "Disabled", "Child")
+	_, hs := HeartbeatFor(ctx, span, 0, time.Millisecond, nil)
+	time.Sleep(20 * time.Millisecond)
+	hs.End()
+
+	if n := len(parent.(sdktrace.ReadOnlySpan).Events()); n != 0 {
+		t.Errorf("got %d heartbeat events on the parent, want none", n)
+	}
+}
+
+func TestHeartbeatFromContext(t *testing.T) {
+	withRecorder(t)
+	ctx, span := Span(context.Background(), "Test", "Op")
+	_, hs := Heartbeat(ctx, 0, time.Millisecond, nil)
+	defer hs.End()
+
+	ro := span.(sdktrace.ReadOnlySpan)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ro.Events()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeat event recorded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if name := ro.Events()[0].Name; name != "still running" {
+		t.Errorf("got event %q, want still running", name)
+	}
+}
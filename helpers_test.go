@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	multihash "github.com/multiformats/go-multihash"
+)
+
+// withRecorder installs a global tracer provider that samples every span and records them
+func withRecorder(t testing.TB) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(rec),
+	))
+	return rec
+}
+
+// attributeMap renders attributes as a map of keys to emitted values
+func attributeMap(attrs []attribute.KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+// mustMultihash returns the sha2-256 multihash of s
+func mustMultihash(t testing.TB, s string) multihash.Multihash {
+	t.Helper()
+	mh, err := multihash.Sum([]byte(s), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatalf("hashing %q: %v", s, err)
+	}
+	return mh
+}
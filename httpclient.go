@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Roles of the destinations of outbound HTTP requests made by IPFS subsystems
+const (
+	HTTPRoleDoH              = "doh"
+	HTTPRoleDelegatedRouting = "delegated_routing"
+	HTTPRoleRemotePinning    = "remote_pinning"
+	HTTPRoleGateway          = "gateway"
+)
+
+// NewHTTPClient returns a copy of the base client, or of http.DefaultClient if base is nil, whose
+// requests are traced. Each request is covered by a client span recording the role of the destination,
+// which should be one of the HTTPRole constants, and the trace context is injected into the request
+// headers using the global propagator. The span ends when the response headers have been received.
+func NewHTTPClient(role string, base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	c := *base
+	c.Transport = NewHTTPTransport(role, base.Transport)
+	return &c
+}
+
+// NewHTTPTransport wraps an http.RoundTripper, or http.DefaultTransport if next is nil, so that each
+// request is traced as described by NewHTTPClient
+func NewHTTPTransport(role string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &tracedTransport{role: role, next: next}
+}
+
+type tracedTransport struct {
+	role string
+	next http.RoundTripper
+}
+
+func (t *tracedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := Span(r.Context(), "HTTPClient", r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.client.role", t.role),
+			semconv.HTTPMethodKey.String(r.Method),
+			semconv.HTTPSchemeKey.String(r.URL.Scheme),
+			semconv.HTTPHostKey.String(r.URL.Host),
+			semconv.HTTPTargetKey.String(validString(r.URL.Path)),
+		),
+	)
+	defer span.End()
+
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		if span.IsRecording() {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return resp, err
+	}
+	if span.IsRecording() {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+		if resp.StatusCode >= 500 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+// Package idgen provides an OpenTelemetry ID generator that lets spans be attributed to the IPFS node
+// that recorded them.
+package idgen
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PrefixLen is the number of bytes at the start of each span id that identify the node
+const PrefixLen = 2
+
+// Generator is an ID generator that embeds a short prefix derived from a node identifier, such as
+// a peer id, at the start of every span id, so that collectors receiving traces from many nodes can
+// attribute spans to nodes even if intermediaries strip resource data. Trace ids are shared by all
+// nodes taking part in a trace and so are left entirely random, as required by W3C trace context.
+type Generator struct {
+	prefix [PrefixLen]byte
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+var _ sdktrace.IDGenerator = (*Generator)(nil)
+
+// New creates a Generator embedding the prefix derived from nodeID
+func New(nodeID string) *Generator {
+	var seed [8]byte
+	_, _ = crand.Read(seed[:])
+
+	g := &Generator{
+		rng: rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:])))),
+	}
+	copy(g.prefix[:], NodePrefix(nodeID))
+	return g
+}
+
+func (g *Generator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var tid trace.TraceID
+	for !tid.IsValid() {
+		_, _ = g.rng.Read(tid[:])
+	}
+	return tid, g.newSpanID()
+}
+
+func (g *Generator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.newSpanID()
+}
+
+// newSpanID must be called with the lock held
+func (g *Generator) newSpanID() trace.SpanID {
+	var sid trace.SpanID
+	copy(sid[:], g.prefix[:])
+	for {
+		_, _ = g.rng.Read(sid[PrefixLen:])
+		if sid.IsValid() {
+			return sid
+		}
+	}
+}
+
+// NodePrefix returns the span id prefix used for the node identifier
+func NodePrefix(nodeID string) []byte {
+	sum := sha256.Sum256([]byte(nodeID))
+	return sum[:PrefixLen]
+}
+
+// HasNodePrefix reports whether a span id carries the prefix of the node identifier. Span ids from
+// other generators match by chance with a probability of 1 in 65536.
+func HasNodePrefix(id trace.SpanID, nodeID string) bool {
+	return bytes.Equal(id[:PrefixLen], NodePrefix(nodeID))
+}
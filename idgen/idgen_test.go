@@ -0,0 +1,57 @@
+package idgen
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestGeneratorEmbedsNodePrefix(t *testing.T) {
+	g := New("peer-a")
+	traces := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		tid, sid := g.NewIDs(context.Background())
+		if !tid.IsValid() || !sid.IsValid() {
+			t.Fatalf("got invalid ids %s %s", tid, sid)
+		}
+		if !HasNodePrefix(sid, "peer-a") {
+			t.Errorf("span id %s lacks the node prefix %x", sid, NodePrefix("peer-a"))
+		}
+		if traces[tid.String()] {
+			t.Errorf("trace id %s repeated", tid)
+		}
+		traces[tid.String()] = true
+
+		if child := g.NewSpanID(context.Background(), tid); !HasNodePrefix(child, "peer-a") || child == sid {
+			t.Errorf("child span id %s: want a new id with the node prefix", child)
+		}
+	}
+}
+
+func TestNodePrefixDistinguishesNodes(t *testing.T) {
+	if string(NodePrefix("peer-a")) == string(NodePrefix("peer-b")) {
+		t.Fatal("test nodes share a prefix")
+	}
+	_, sid := New("peer-a").NewIDs(context.Background())
+	if HasNodePrefix(sid, "peer-b") {
+		t.Errorf("span id %s from peer-a matched the prefix of peer-b", sid)
+	}
+}
+
+func TestGeneratorWithTracerProvider(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithIDGenerator(New("peer-a")), sdktrace.WithSpanProcessor(rec))
+
+	ctx, root := tp.Tracer("").Start(context.Background(), "root")
+	_, child := tp.Tracer("").Start(ctx, "child")
+	child.End()
+	root.End()
+
+	for _, s := range rec.Ended() {
+		if !HasNodePrefix(s.SpanContext().SpanID(), "peer-a") {
+			t.Errorf("span %q has id %s without the node prefix", s.Name(), s.SpanContext().SpanID())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Classifications of IPNS republish failures recorded by RepublishCycle.EndKey
+const (
+	RepublishFailureKeystore = "keystore" // the private key could not be loaded
+	RepublishFailureRecord   = "record"   // the existing record could not be read or a new one created
+	RepublishFailureRouting  = "routing"  // the record could not be put to the routing system
+	RepublishFailureTimeout  = "timeout"  // the operation exceeded its deadline
+	RepublishFailureOther    = "other"
+)
+
+// RepublishCycle traces one cycle of the background IPNS republisher. Each cycle is the root of its
+// own trace, linked to the previous cycle, with a child span for each key republished.
+type RepublishCycle struct {
+	ctx      context.Context
+	span     trace.Span
+	keys     int64
+	failures int64
+}
+
+// StartRepublishCycle starts the root span of a republish cycle. The previous argument is the span
+// context returned by End for the previous cycle, which is linked so that consecutive cycles can be
+// followed. It may be an empty span context for the first cycle.
+func StartRepublishCycle(ctx context.Context, componentName string, previous trace.SpanContext) *RepublishCycle {
+	opts := []trace.SpanStartOption{trace.WithNewRoot()}
+	if previous.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: previous}))
+	}
+	ctx, span := Span(ctx, componentName, "RepublishCycle", opts...)
+	return &RepublishCycle{ctx: ctx, span: span}
+}
+
+// Context returns the context holding the span of the cycle
+func (c *RepublishCycle) Context() context.Context {
+	return c.ctx
+}
+
+// SpanForKey starts a child span of the cycle covering the republishing of the named key
+func (c *RepublishCycle) SpanForKey(componentName string, key string) (context.Context, trace.Span) {
+	atomic.AddInt64(&c.keys, 1)
+	return SpanWithStringAttribute(c.ctx, componentName, "RepublishKey", "ipns.key", key)
+}
+
+// EndKey ends the span of a key started by SpanForKey. A non-nil error is recorded along with the
+// failure classification, which should be one of the RepublishFailure constants. If the failure is
+// empty it is derived from the error.
+func (c *RepublishCycle) EndKey(span trace.Span, err error, failure string) {
+	if err != nil {
+		atomic.AddInt64(&c.failures, 1)
+		if failure == "" {
+			failure = RepublishFailureOther
+			if errors.Is(err, context.DeadlineExceeded) {
+				failure = RepublishFailureTimeout
+			}
+		}
+		if span.IsRecording() {
+			span.SetAttributes(attribute.String("ipns.republish.failure", failure))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// End records the number of keys republished and failed on the cycle span and ends it. It returns the
+// span context of the cycle for linking from the next cycle.
+func (c *RepublishCycle) End() trace.SpanContext {
+	if c.span.IsRecording() {
+		failures := atomic.LoadInt64(&c.failures)
+		c.span.SetAttributes(
+			attribute.Int64("ipns.keys.count", atomic.LoadInt64(&c.keys)),
+			attribute.Int64("ipns.failures.count", failures),
+		)
+		if failures > 0 {
+			c.span.SetStatus(codes.Error, "republish failed for some keys")
+		}
+	}
+	c.span.End()
+	return c.span.SpanContext()
+}
@@ -0,0 +1,165 @@
+// Package kubo wires the tracing helpers, samplers and processors of this module together for an IPFS
+// node in a single call.
+package kubo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	tracing "github.com/iand/go-ipfs-tracing"
+	"github.com/iand/go-ipfs-tracing/idgen"
+	"github.com/iand/go-ipfs-tracing/processor"
+	"github.com/iand/go-ipfs-tracing/sampling"
+)
+
+// DefaultServiceName is the service name recorded in the resource when none is configured
+const DefaultServiceName = "kubo"
+
+// Config configures the tracing pipeline built by SetupNode
+type Config struct {
+	// ServiceName is recorded as the service name of the resource. DefaultServiceName is used if it
+	// is empty.
+	ServiceName string
+
+	// Role is recorded as the node role of the resource, such as "gateway", "provider" or "personal"
+	Role string
+
+	// Exporters receive the spans that are kept. Each is fed by its own batch span processor.
+	Exporters []sdktrace.SpanExporter
+
+	// Sampler decides whether local root spans are sampled when no role specific sampler applies.
+	// Child spans follow the decision of their parent. All traces are sampled if it is nil.
+	Sampler sdktrace.Sampler
+
+	// RoleSamplers optionally maps node roles to samplers used in place of Sampler
+	RoleSamplers map[string]sdktrace.Sampler
+
+	// Renames are applied to span names before any other processing
+	Renames []processor.Rename
+
+	// SLOs are evaluated against local root spans
+	SLOs []processor.SLO
+
+	// MinDurations optionally maps component names to the minimum duration of spans passed to the
+	// exporters, as described by processor.NewMinDurationFilter
+	MinDurations map[string]time.Duration
+
+	// SpanRegistry enables the open span registry used by the debug handler and crash hooks
+	SpanRegistry bool
+
+	// JanitorMaxAge, if non-zero, is the age after which open spans are ended as abandoned. It
+	// requires SpanRegistry. The janitor runs every JanitorInterval, or every JanitorMaxAge if the
+	// interval is zero.
+	JanitorMaxAge   time.Duration
+	JanitorInterval time.Duration
+}
+
+// Node holds the parts of the tracing pipeline built by SetupNode
+type Node struct {
+	// Provider is the tracer provider, which has been installed as the global provider
+	Provider *sdktrace.TracerProvider
+
+	// Resource describes the node
+	Resource *resource.Resource
+
+	// Renames reports the hits of the configured rename rules. It is nil if no renames were
+	// configured.
+	Renames *processor.RenameProcessor
+
+	// SLOs reports the statistics of the configured objectives. It is nil if no objectives were
+	// configured.
+	SLOs *processor.SLOProcessor
+
+	// DebugHandler serves the open span registry. It is nil if the registry is not enabled.
+	DebugHandler http.Handler
+
+	// Shutdown stops the janitor, flushes and shuts down the pipeline and runs any functions
+	// registered using tracing.OnShutdown
+	Shutdown func(context.Context) error
+}
+
+// SetupNode builds the tracing pipeline for a node and installs it as the global tracer provider and
+// propagator. The identity, typically the node's peer id, is recorded as the service instance id of the
+// resource and embedded in span ids using idgen. W3C trace context and baggage are propagated. An error
+// flushing or shutting down a previously installed provider is passed to the global error handler.
+func SetupNode(ctx context.Context, cfg Config, identity string) (*Node, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceInstanceIDKey.String(identity),
+	}
+	if cfg.Role != "" {
+		attrs = append(attrs, sampling.NodeRoleAttribute(cfg.Role))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := cfg.Sampler
+	if fallback == nil {
+		fallback = sdktrace.AlwaysSample()
+	}
+	sampler := sampling.NewRoleSampler(res, cfg.RoleSamplers, fallback)
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithIDGenerator(idgen.New(identity)),
+	}
+
+	n := &Node{Resource: res}
+	if len(cfg.Renames) > 0 {
+		n.Renames = processor.NewRenameProcessor(cfg.Renames...)
+		opts = append(opts, sdktrace.WithSpanProcessor(n.Renames))
+	}
+	if len(cfg.SLOs) > 0 {
+		n.SLOs = processor.NewSLOProcessor(cfg.SLOs...)
+		opts = append(opts, sdktrace.WithSpanProcessor(n.SLOs))
+	}
+	for _, exp := range cfg.Exporters {
+		var sp sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exp)
+		if len(cfg.MinDurations) > 0 {
+			sp = processor.NewMinDurationFilter(sp, cfg.MinDurations)
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(sp))
+	}
+
+	n.Provider = sdktrace.NewTracerProvider(opts...)
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	// Reinit installs the new provider before draining the previous one, so a failed drain loses at
+	// most the previous provider's spans and is reported rather than failing the setup
+	if err := tracing.Reinit(ctx, n.Provider, propagator); err != nil {
+		otel.Handle(err)
+	}
+
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	if cfg.SpanRegistry {
+		tracing.EnableSpanRegistry(true)
+		n.DebugHandler = tracing.OpenSpansHandler()
+		if cfg.JanitorMaxAge > 0 {
+			interval := cfg.JanitorInterval
+			if interval <= 0 {
+				interval = cfg.JanitorMaxAge
+			}
+			tracing.StartSpanJanitor(janitorCtx, cfg.JanitorMaxAge, interval)
+		}
+	}
+
+	n.Shutdown = func(ctx context.Context) error {
+		stopJanitor()
+		return tracing.Shutdown(ctx)
+	}
+	return n, nil
+}
@@ -0,0 +1,40 @@
+package kubo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// failingExporter is an exporter whose shutdown fails
+type failingExporter struct {
+	*tracetest.NoopExporter
+}
+
+func (failingExporter) Shutdown(context.Context) error { return errors.New("shutdown failed") }
+
+func TestSetupNodeReportsFailedDrain(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(failingExporter{tracetest.NewNoopExporter()}),
+	))
+
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) { handled = append(handled, err) }))
+
+	n, err := SetupNode(context.Background(), Config{}, "peer")
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	defer n.Shutdown(context.Background())
+
+	if otel.GetTracerProvider() != n.Provider {
+		t.Error("new provider is not installed as the global provider")
+	}
+	if len(handled) != 1 {
+		t.Errorf("got %d errors passed to the error handler, want the drain error", len(handled))
+	}
+}
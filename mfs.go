@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// Reasons for an MFS flush recorded by SpanForMFSFlush
+const (
+	MFSFlushExplicit  = "explicit"  // requested by a user, such as by files flush
+	MFSFlushTimer     = "timer"     // the periodic flush interval elapsed
+	MFSFlushThreshold = "threshold" // the number of unflushed operations reached a limit
+)
+
+// SpanForMFSFlush starts a span covering an MFS flush, recording why the flush was triggered and the
+// number of operations batched into it. The trigger should be one of the MFSFlush constants. Callers
+// should end the span using EndMFSFlush.
+func SpanForMFSFlush(ctx context.Context, componentName string, trigger string, ops int) (context.Context, trace.Span) {
+	return Span(ctx, componentName, "Flush", trace.WithAttributes(
+		attribute.String("mfs.flush.trigger", trigger),
+		attribute.Int("mfs.ops.count", ops),
+	))
+}
+
+// EndMFSFlush ends a span started by SpanForMFSFlush, recording the new root cid of the filesystem if
+// the flush succeeded or the error if it failed
+func EndMFSFlush(span trace.Span, componentName string, root cid.Cid, err error) {
+	if err == nil && span.IsRecording() {
+		span.SetAttributes(attribute.String("mfs.root", cidString(root)))
+	}
+	EndSpan(span, componentName, err)
+}
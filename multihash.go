@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	multihash "github.com/multiformats/go-multihash"
+)
+
+// SpanWithMultihashAttribute is a helper function to assist the common pattern of starting a new span
+// with a multihash and its decomposition as attributes, for code paths such as blockstore keys and
+// provider records that operate on multihashes rather than full CIDs
+func SpanWithMultihashAttribute(ctx context.Context, componentName string, spanName string, mh multihash.Multihash) (context.Context, trace.Span) {
+	ctx, span := Span(ctx, componentName, spanName)
+	if span.IsRecording() {
+		span.SetAttributes(MultihashAttributes(mh)...)
+	}
+	return ctx, span
+}
+
+// MultihashAttribute creates a span attribute with a standard name for representing a multihash
+func MultihashAttribute(mh multihash.Multihash) attribute.KeyValue {
+	return attribute.String("multihash", mh.B58String())
+}
+
+// MultihashAttributes creates span attributes with standard names for representing a multihash along
+// with the name of its hash function and the length of its digest. Only the multihash itself is
+// included if it cannot be decoded.
+func MultihashAttributes(mh multihash.Multihash) []attribute.KeyValue {
+	dmh, err := multihash.Decode(mh)
+	if err != nil {
+		return []attribute.KeyValue{MultihashAttribute(mh)}
+	}
+	return []attribute.KeyValue{
+		MultihashAttribute(mh),
+		attribute.String("multihash.algorithm", dmh.Name),
+		BytesAttribute("multihash.digest", int64(dmh.Length)),
+	}
+}
@@ -0,0 +1,164 @@
+// Package otlpreceiver provides a lightweight OTLP/HTTP trace receiver that an IPFS node can host to
+// accept spans from sidecar processes and forward them through its own OTLP export client.
+package otlpreceiver
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Path is the path of the OTLP/HTTP trace endpoint
+const Path = "/v1/traces"
+
+// MaxRequestSize is the largest request body, after decompression, accepted by a Receiver
+const MaxRequestSize = 4 << 20
+
+// Receiver is an http handler implementing the OTLP/HTTP trace endpoint for protobuf encoded requests.
+// Received spans are forwarded to an OTLP client, typically the one used by the node's own exporter,
+// after the node's resource attributes have been merged into the resource of each batch. Attributes
+// already present on a received resource, such as the sidecar's service name, are left unchanged.
+type Receiver struct {
+	client   otlptrace.Client
+	resource []*commonpb.KeyValue
+}
+
+var _ http.Handler = (*Receiver)(nil)
+
+// New creates a Receiver that forwards spans to client. The client must already have been started.
+// The resource may be nil if no attributes should be merged.
+func New(client otlptrace.Client, res *resource.Resource) *Receiver {
+	r := &Receiver{client: client}
+	if res != nil {
+		for _, kv := range res.Attributes() {
+			r.resource = append(r.resource, keyValue(kv))
+		}
+	}
+	return r
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, "invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, MaxRequestSize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(data) > MaxRequestSize {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var export coltracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(data, &export); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	for _, rs := range export.ResourceSpans {
+		if rs.Resource == nil {
+			rs.Resource = &resourcepb.Resource{}
+		}
+		rs.Resource.Attributes = r.merge(rs.Resource.Attributes)
+	}
+
+	if err := r.client.UploadTraces(req.Context(), export.ResourceSpans); err != nil {
+		http.Error(w, "failed to forward spans", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
+
+// merge adds the receiver's resource attributes that are not already present to attrs
+func (r *Receiver) merge(attrs []*commonpb.KeyValue) []*commonpb.KeyValue {
+	present := make(map[string]bool, len(attrs))
+	for _, kv := range attrs {
+		present[kv.Key] = true
+	}
+	for _, kv := range r.resource {
+		if !present[kv.Key] {
+			attrs = append(attrs, kv)
+		}
+	}
+	return attrs
+}
+
+func keyValue(kv attribute.KeyValue) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: string(kv.Key), Value: anyValue(kv.Value)}
+}
+
+func anyValue(v attribute.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.BOOLSLICE:
+		var values []*commonpb.AnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: b}})
+		}
+		return arrayValue(values)
+	case attribute.INT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}})
+		}
+		return arrayValue(values)
+	case attribute.FLOAT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}})
+		}
+		return arrayValue(values)
+	case attribute.STRINGSLICE:
+		var values []*commonpb.AnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}})
+		}
+		return arrayValue(values)
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+	}
+}
+
+func arrayValue(values []*commonpb.AnyValue) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+}
@@ -0,0 +1,167 @@
+package otlpreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// testClient records the spans uploaded to it
+type testClient struct {
+	uploaded []*tracepb.ResourceSpans
+	err      error
+}
+
+func (c *testClient) Start(context.Context) error { return nil }
+func (c *testClient) Stop(context.Context) error  { return nil }
+
+func (c *testClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.uploaded = append(c.uploaded, spans...)
+	return nil
+}
+
+func stringAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func exportRequest(t *testing.T) []byte {
+	t.Helper()
+	data, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", "sidecar")}},
+			InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+				Spans: []*tracepb.Span{{Name: "Sidecar.Fetch", TraceId: bytes.Repeat([]byte{1}, 16), SpanId: bytes.Repeat([]byte{2}, 8)}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return data
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func post(r http.Handler, body []byte, contentType string, encoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReceiverRoundTrip(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String("service.name", "node"), attribute.String("service.instance.id", "peer"))
+
+	for _, encoding := range []string{"", "gzip"} {
+		client := &testClient{}
+		r := New(client, res)
+
+		body := exportRequest(t)
+		if encoding == "gzip" {
+			body = gzipped(t, body)
+		}
+		w := post(r, body, "application/x-protobuf", encoding)
+		if w.Code != http.StatusOK {
+			t.Fatalf("encoding %q: got status %d, want 200: %s", encoding, w.Code, w.Body)
+		}
+		if err := proto.Unmarshal(w.Body.Bytes(), &coltracepb.ExportTraceServiceResponse{}); err != nil {
+			t.Errorf("encoding %q: invalid response: %v", encoding, err)
+		}
+
+		if len(client.uploaded) != 1 {
+			t.Fatalf("encoding %q: got %d resource spans forwarded, want 1", encoding, len(client.uploaded))
+		}
+		rs := client.uploaded[0]
+		if name := rs.InstrumentationLibrarySpans[0].Spans[0].Name; name != "Sidecar.Fetch" {
+			t.Errorf("encoding %q: got span %q, want Sidecar.Fetch", encoding, name)
+		}
+		attrs := map[string]string{}
+		for _, kv := range rs.Resource.Attributes {
+			attrs[kv.Key] = kv.Value.GetStringValue()
+		}
+		if attrs["service.name"] != "sidecar" || attrs["service.instance.id"] != "peer" || len(attrs) != 2 {
+			t.Errorf("encoding %q: got resource %v, want the sidecar's service name and the node's instance id", encoding, attrs)
+		}
+	}
+}
+
+func TestReceiverRejectsInvalidRequests(t *testing.T) {
+	valid := exportRequest(t)
+	oversized := make([]byte, MaxRequestSize+1)
+
+	testCases := []struct {
+		name        string
+		body        []byte
+		contentType string
+		encoding    string
+		want        int
+	}{
+		{name: "missing content type", body: valid, want: http.StatusUnsupportedMediaType},
+		{name: "json content type", body: valid, contentType: "application/json", want: http.StatusUnsupportedMediaType},
+		{name: "malformed body", body: []byte{0xff, 0xff, 0xff}, contentType: "application/x-protobuf", want: http.StatusBadRequest},
+		{name: "invalid gzip", body: valid, contentType: "application/x-protobuf", encoding: "gzip", want: http.StatusBadRequest},
+		{name: "truncated gzip", body: gzipped(t, valid)[:20], contentType: "application/x-protobuf", encoding: "gzip", want: http.StatusBadRequest},
+		{name: "oversized body", body: oversized, contentType: "application/x-protobuf", want: http.StatusRequestEntityTooLarge},
+		{name: "oversized after decompression", body: gzipped(t, oversized), contentType: "application/x-protobuf", encoding: "gzip", want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &testClient{}
+			w := post(New(client, nil), tc.body, tc.contentType, tc.encoding)
+			if w.Code != tc.want {
+				t.Errorf("got status %d, want %d", w.Code, tc.want)
+			}
+			if len(client.uploaded) != 0 {
+				t.Errorf("got %d resource spans forwarded, want none", len(client.uploaded))
+			}
+		})
+	}
+}
+
+func TestReceiverRejectsOtherMethods(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(&testClient{}, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path, nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("got status %d and Allow %q, want 405 and POST", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestReceiverReportsForwardingFailure(t *testing.T) {
+	w := post(New(&testClient{err: errors.New("unavailable")}, nil), exportRequest(t), "application/x-protobuf", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503", w.Code)
+	}
+}
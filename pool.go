@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The pools below hold the slices used to assemble span start options on hot paths. Slices taken
+// from a pool are only used for the duration of a single call to Tracer.Start. This relies on the
+// tracer copying the options it needs rather than retaining the slices, which the OpenTelemetry
+// SDK does.
+var (
+	optionPool = sync.Pool{
+		New: func() interface{} {
+			s := make([]trace.SpanStartOption, 0, 4)
+			return &s
+		},
+	}
+
+	attributePool = sync.Pool{
+		New: func() interface{} {
+			s := make([]attribute.KeyValue, 0, 4)
+			return &s
+		},
+	}
+)
+
+func getOptions() *[]trace.SpanStartOption {
+	return optionPool.Get().(*[]trace.SpanStartOption)
+}
+
+func putOptions(s *[]trace.SpanStartOption) {
+	for i := range *s {
+		(*s)[i] = nil
+	}
+	*s = (*s)[:0]
+	optionPool.Put(s)
+}
+
+func getAttributes() *[]attribute.KeyValue {
+	return attributePool.Get().(*[]attribute.KeyValue)
+}
+
+func putAttributes(s *[]attribute.KeyValue) {
+	for i := range *s {
+		(*s)[i] = attribute.KeyValue{}
+	}
+	*s = (*s)[:0]
+	attributePool.Put(s)
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func BenchmarkSpanWithStringAttribute(b *testing.B) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample())))
+	ctx := WithCommonAttributes(context.Background(), attribute.String("request_id", "bench"))
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, span := SpanWithStringAttribute(ctx, "Bench", "Span", "key", "value")
+			span.End()
+		}
+	})
+
+	// unpooled assembles the start options as the helpers did before the pools were introduced
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			opts := unpooledOptions(trace.WithAttributes(attribute.String("key", "value")))
+			opts = append([]trace.SpanStartOption{trace.WithAttributes(CommonAttributesFromContext(ctx)...)}, opts...)
+			_, span := otel.Tracer("").Start(ctx, fmt.Sprintf("%s.%s", "Bench", "Span"), opts...)
+			span.End()
+		}
+	})
+}
+
+//go:noinline
+func unpooledOptions(opts ...trace.SpanStartOption) []trace.SpanStartOption {
+	return opts
+}
+
+// TestPooledSlicesNotRetained checks that spans started concurrently keep their own attributes once
+// the pooled slices used to start them have been reused. Run it with -race.
+func TestPooledSlicesNotRetained(t *testing.T) {
+	rec := withRecorder(t)
+	EnableSpanRegistry(true)
+	defer EnableSpanRegistry(false)
+
+	ctx := WithCommonAttributes(context.Background(), attribute.String("request_id", "race"))
+
+	const goroutines, spans = 8, 200
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < spans; i++ {
+				v := fmt.Sprintf("%d-%d", g, i)
+				_, span := SpanWithStringAttribute(ctx, "Test", "Span", "key", v)
+				span.SetAttributes(attribute.String("want", v))
+				span.End()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	ended := rec.Ended()
+	if len(ended) != goroutines*spans {
+		t.Fatalf("got %d spans, want %d", len(ended), goroutines*spans)
+	}
+	for _, s := range ended {
+		got := attributeMap(s.Attributes())
+		if got["key"] != got["want"] {
+			t.Errorf("span has key %q, want %q", got["key"], got["want"])
+		}
+		if got["request_id"] != "race" {
+			t.Errorf("span has request_id %q, want %q", got["request_id"], "race")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// maxDeferredSpans is the number of spans an IdleExporter holds before exporting regardless of load
+const maxDeferredSpans = 100000
+
+// defaultIdleCheckInterval is the interval used by an IdleExporter when the one given is not positive
+const defaultIdleCheckInterval = time.Second
+
+// IdleExporter is a span exporter wrapper that defers exporting spans while the node is busy, so that
+// exporting a large backlog does not compete with content serving during peak load. Deferred spans
+// are exported once the node becomes idle or when the oldest deferred span has waited for the maximum
+// delay. It is intended to sit between a batch span processor and the real exporter.
+type IdleExporter struct {
+	next     sdktrace.SpanExporter
+	idle     func() bool
+	maxDelay time.Duration
+
+	mu       sync.Mutex
+	deferred []sdktrace.ReadOnlySpan
+	oldest   time.Time
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+var _ sdktrace.SpanExporter = (*IdleExporter)(nil)
+
+// NewIdleExporter creates an IdleExporter that passes spans to next. The idle function reports whether
+// the node is below its CPU and bandwidth thresholds and is polled every checkInterval while spans are
+// deferred. No span is deferred for longer than maxDelay. A checkInterval that is not positive is
+// replaced by a default of one second.
+func NewIdleExporter(next sdktrace.SpanExporter, idle func() bool, maxDelay time.Duration, checkInterval time.Duration) *IdleExporter {
+	if checkInterval <= 0 {
+		checkInterval = defaultIdleCheckInterval
+	}
+
+	e := &IdleExporter{
+		next:     next,
+		idle:     idle,
+		maxDelay: maxDelay,
+		done:     make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run(checkInterval)
+	return e
+}
+
+func (e *IdleExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	if len(e.deferred) == 0 && e.idle() {
+		e.mu.Unlock()
+		return e.next.ExportSpans(ctx, spans)
+	}
+	if len(e.deferred) == 0 {
+		e.oldest = time.Now()
+	}
+	e.deferred = append(e.deferred, spans...)
+	if len(e.deferred) < maxDeferredSpans {
+		e.mu.Unlock()
+		return nil
+	}
+	deferred := e.take()
+	e.mu.Unlock()
+
+	return e.next.ExportSpans(ctx, deferred)
+}
+
+// Shutdown exports any deferred spans and shuts down the next exporter. It is safe to call more than
+// once.
+func (e *IdleExporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.done) })
+	e.wg.Wait()
+
+	e.mu.Lock()
+	deferred := e.take()
+	e.mu.Unlock()
+
+	if len(deferred) > 0 {
+		if err := e.next.ExportSpans(ctx, deferred); err != nil {
+			otel.Handle(err)
+		}
+	}
+	return e.next.Shutdown(ctx)
+}
+
+func (e *IdleExporter) run(checkInterval time.Duration) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		}
+
+		e.mu.Lock()
+		if len(e.deferred) == 0 || (time.Since(e.oldest) < e.maxDelay && !e.idle()) {
+			e.mu.Unlock()
+			continue
+		}
+		deferred := e.take()
+		e.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), e.maxDelay)
+		if err := e.next.ExportSpans(ctx, deferred); err != nil {
+			otel.Handle(err)
+		}
+		cancel()
+	}
+}
+
+// take removes and returns the deferred spans. The caller must hold the lock.
+func (e *IdleExporter) take() []sdktrace.ReadOnlySpan {
+	deferred := e.deferred
+	e.deferred = nil
+	return deferred
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestIdleExporterNonPositiveCheckInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		e := NewIdleExporter(tracetest.NewNoopExporter(), func() bool { return true }, time.Second, interval)
+		if err := e.Shutdown(context.Background()); err != nil {
+			t.Errorf("interval %v: shutdown: %v", interval, err)
+		}
+	}
+}
+
+func TestIdleExporterShutdownTwice(t *testing.T) {
+	next := &countingExporter{}
+	e := NewIdleExporter(next, func() bool { return false }, time.Hour, time.Hour)
+
+	stub := tracetest.SpanStub{Name: "Bitswap.Serve"}
+	if err := e.ExportSpans(context.Background(), []sdktrace.ReadOnlySpan{stub.Snapshot()}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("first shutdown: %v", err)
+	}
+	if got := next.count; got != 1 {
+		t.Errorf("deferred spans exported on shutdown: got %d, want 1", got)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second shutdown: %v", err)
+	}
+}
+
+// countingExporter counts the spans it is given. Unlike tracetest.InMemoryExporter it keeps the count
+// after it has been shut down.
+type countingExporter struct {
+	count int
+}
+
+func (e *countingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.count += len(spans)
+	return nil
+}
+
+func (e *countingExporter) Shutdown(ctx context.Context) error { return nil }
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// MinDurationFilter is a span processor that drops completed spans shorter than a minimum duration
+// configured for their component, a blunt but effective control of trace volume. Spans with an error
+// status and local root spans are always kept so that failures and request outlines are never lost.
+// The component of a span is the part of its name before the first dot, following the naming used by
+// tracing.Span. Dropping a span leaves any of its children that are kept without their parent.
+type MinDurationFilter struct {
+	next      sdktrace.SpanProcessor
+	durations map[string]time.Duration
+	fallback  time.Duration
+}
+
+var _ sdktrace.SpanProcessor = (*MinDurationFilter)(nil)
+
+// NewMinDurationFilter creates a MinDurationFilter passing kept spans to next. The durations map
+// component names to their minimum span duration. The duration mapped to the empty string is used for
+// components that are not listed, which are otherwise never filtered. The map is copied so later
+// changes to it have no effect.
+func NewMinDurationFilter(next sdktrace.SpanProcessor, durations map[string]time.Duration) *MinDurationFilter {
+	p := &MinDurationFilter{
+		next:      next,
+		durations: make(map[string]time.Duration, len(durations)),
+	}
+	for name, d := range durations {
+		if name == "" {
+			p.fallback = d
+			continue
+		}
+		p.durations[name] = d
+	}
+	return p
+}
+
+func (p *MinDurationFilter) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *MinDurationFilter) OnEnd(s sdktrace.ReadOnlySpan) {
+	if p.keep(s) {
+		p.next.OnEnd(s)
+	}
+}
+
+func (p *MinDurationFilter) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *MinDurationFilter) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *MinDurationFilter) keep(s sdktrace.ReadOnlySpan) bool {
+	if !s.Parent().IsValid() || s.Parent().IsRemote() || s.Status().Code == codes.Error {
+		return true
+	}
+	component := s.Name()
+	if i := strings.IndexByte(component, '.'); i >= 0 {
+		component = component[:i]
+	}
+	min, ok := p.durations[component]
+	if !ok {
+		min = p.fallback
+	}
+	return s.EndTime().Sub(s.StartTime()) >= min
+}
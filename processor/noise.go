@@ -0,0 +1,137 @@
+// Package processor provides span processors and exporter wrappers that transform or filter spans
+// recorded by IPFS nodes before they are exported.
+package processor
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NoiseExporter is a span exporter wrapper that perturbs the values of selected integer count
+// attributes, such as the number of blocks served or peers contacted, with Laplace noise before
+// passing spans to the next exporter. It is intended for operators who share traces publicly but
+// consider exact traffic numbers sensitive. Noisy values are rounded and never negative.
+type NoiseExporter struct {
+	next  sdktrace.SpanExporter
+	keys  map[attribute.Key]bool
+	scale float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+var _ sdktrace.SpanExporter = (*NoiseExporter)(nil)
+
+// MinNoiseEpsilon is the smallest privacy parameter used by a NoiseExporter. Smaller values, including
+// zero and negative values for which the noise is undefined, are raised to it.
+const MinNoiseEpsilon = 0.01
+
+// NewNoiseExporter creates a NoiseExporter that adds noise to the attributes of spans and span events
+// with the given keys. The epsilon is the privacy parameter for a count with a sensitivity of one:
+// smaller values add more noise. An epsilon below MinNoiseEpsilon is raised to it.
+func NewNoiseExporter(next sdktrace.SpanExporter, epsilon float64, keys ...attribute.Key) *NoiseExporter {
+	if !(epsilon >= MinNoiseEpsilon) {
+		epsilon = MinNoiseEpsilon
+	}
+
+	var seed [8]byte
+	_, _ = crand.Read(seed[:])
+
+	e := &NoiseExporter{
+		next:  next,
+		keys:  make(map[attribute.Key]bool, len(keys)),
+		scale: 1 / epsilon,
+		rng:   rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:])))),
+	}
+	for _, k := range keys {
+		e.keys[k] = true
+	}
+	return e
+}
+
+func (e *NoiseExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	out := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		out[i] = e.perturb(s)
+	}
+	return e.next.ExportSpans(ctx, out)
+}
+
+func (e *NoiseExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+func (e *NoiseExporter) perturb(s sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	if !e.hasKeys(s.Attributes()) && !e.eventsHaveKeys(s.Events()) {
+		return s
+	}
+
+	stub := tracetest.SpanStubFromReadOnlySpan(s)
+	stub.Attributes = e.perturbAttributes(stub.Attributes)
+	events := make([]sdktrace.Event, len(stub.Events))
+	for i, ev := range stub.Events {
+		ev.Attributes = e.perturbAttributes(ev.Attributes)
+		events[i] = ev
+	}
+	stub.Events = events
+	return stub.Snapshot()
+}
+
+func (e *NoiseExporter) hasKeys(attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if e.keys[kv.Key] && kv.Value.Type() == attribute.INT64 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *NoiseExporter) eventsHaveKeys(events []sdktrace.Event) bool {
+	for _, ev := range events {
+		if e.hasKeys(ev.Attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *NoiseExporter) perturbAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	if !e.hasKeys(attrs) {
+		return attrs
+	}
+	out := make([]attribute.KeyValue, len(attrs))
+	for i, kv := range attrs {
+		if e.keys[kv.Key] && kv.Value.Type() == attribute.INT64 {
+			v := math.Round(float64(kv.Value.AsInt64()) + e.laplace())
+			if v < 0 {
+				v = 0
+			}
+			kv = attribute.Int64(string(kv.Key), int64(v))
+		}
+		out[i] = kv
+	}
+	return out
+}
+
+// laplace returns a sample from a Laplace distribution centred on zero
+func (e *NoiseExporter) laplace() float64 {
+	e.mu.Lock()
+	u := e.rng.Float64() - 0.5
+	for u == -0.5 {
+		u = e.rng.Float64() - 0.5
+	}
+	e.mu.Unlock()
+
+	if u < 0 {
+		return e.scale * math.Log(1+2*u)
+	}
+	return -e.scale * math.Log(1-2*u)
+}
@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNoiseExporterClampsEpsilon(t *testing.T) {
+	for _, epsilon := range []float64{0, -1, math.NaN(), math.Inf(-1)} {
+		e := NewNoiseExporter(tracetest.NewNoopExporter(), epsilon, "blocks.count")
+		if math.IsInf(e.scale, 0) || math.IsNaN(e.scale) || e.scale <= 0 {
+			t.Errorf("epsilon %v: got scale %v, want a finite positive scale", epsilon, e.scale)
+		}
+	}
+}
+
+func TestNoiseExporterPerturbsCounts(t *testing.T) {
+	next := tracetest.NewInMemoryExporter()
+	e := NewNoiseExporter(next, 0, "blocks.count")
+
+	stub := tracetest.SpanStub{
+		Name:       "Bitswap.Serve",
+		Attributes: []attribute.KeyValue{attribute.Int("blocks.count", 10), attribute.String("peer", "p")},
+	}
+	if err := e.ExportSpans(context.Background(), []sdktrace.ReadOnlySpan{stub.Snapshot()}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	got := next.GetSpans()
+	if len(got) != 1 {
+		t.Fatalf("got %d spans, want 1", len(got))
+	}
+	for _, kv := range got[0].Attributes {
+		switch kv.Key {
+		case "blocks.count":
+			if kv.Value.AsInt64() < 0 {
+				t.Errorf("blocks.count: got %d, want a non-negative count", kv.Value.AsInt64())
+			}
+		case "peer":
+			if kv.Value.AsString() != "p" {
+				t.Errorf("peer: got %q, want unchanged", kv.Value.AsString())
+			}
+		}
+	}
+}
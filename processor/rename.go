@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Rename is a rule that renames spans whose names match a pattern
+type Rename struct {
+	// Pattern is matched against the full span name
+	Pattern *regexp.Regexp
+
+	// Replacement is the new name, which may refer to submatches of the pattern as described by
+	// regexp.Regexp.Expand, such as "Gateway.$1"
+	Replacement string
+}
+
+// RenameProcessor is a span processor that renames spans as they start using regular expressions,
+// such as to map names used by older releases onto current names so that dashboards stay stable
+// across versions with differing instrumentation. Only the first matching rule is applied. Spans are
+// renamed in OnStart so the processor must be registered with the tracer provider before any
+// processor that exports spans. It does not pass spans on.
+type RenameProcessor struct {
+	rules []Rename
+	hits  []int64
+}
+
+var _ sdktrace.SpanProcessor = (*RenameProcessor)(nil)
+
+// NewRenameProcessor creates a RenameProcessor applying the given rules in order
+func NewRenameProcessor(rules ...Rename) *RenameProcessor {
+	return &RenameProcessor{
+		rules: rules,
+		hits:  make([]int64, len(rules)),
+	}
+}
+
+func (p *RenameProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	name := s.Name()
+	for i, r := range p.rules {
+		match := r.Pattern.FindStringSubmatchIndex(name)
+		if match == nil || match[0] != 0 || match[1] != len(name) {
+			continue
+		}
+		s.SetName(string(r.Pattern.ExpandString(nil, r.Replacement, name, match)))
+		atomic.AddInt64(&p.hits[i], 1)
+		return
+	}
+}
+
+func (p *RenameProcessor) OnEnd(s sdktrace.ReadOnlySpan) {}
+
+func (p *RenameProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (p *RenameProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Hits returns the number of spans renamed by each rule, in the order the rules were passed to
+// NewRenameProcessor, for publishing as metrics
+func (p *RenameProcessor) Hits() []int64 {
+	hits := make([]int64, len(p.hits))
+	for i := range p.hits {
+		hits[i] = atomic.LoadInt64(&p.hits[i])
+	}
+	return hits
+}
@@ -0,0 +1,178 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	tracing "github.com/iand/go-ipfs-tracing"
+)
+
+// maxSkewSpans is the number of spans for which a SkewExporter remembers timings, both of parents
+// awaiting export and of exported spans whose remote children may arrive later
+const maxSkewSpans = 10000
+
+// SkewExporter is a span exporter wrapper that annotates spans whose remote children appear to start
+// before the span started or end after it ended. Within a trace this can only happen when the clocks of
+// the nodes involved disagree, so such timings are marked as suspect rather than being taken at face
+// value. Spans are annotated with a clock.skew_suspected attribute and clock.skew_ms, the largest
+// amount by which a child falls outside its parent. The parent is annotated when its remote children
+// are exported before or with it. A remote child exported after its parent, as is usual when the child
+// is reported by another node, is annotated itself since the parent has already been passed on. It is
+// intended for collectors that receive spans from several nodes, such as an otlpreceiver.Receiver,
+// since the parent and child must pass through the same exporter.
+type SkewExporter struct {
+	next sdktrace.SpanExporter
+
+	mu       sync.Mutex
+	children *timingCache
+	parents  *timingCache
+}
+
+var _ sdktrace.SpanExporter = (*SkewExporter)(nil)
+
+// NewSkewExporter creates a SkewExporter that passes spans to next. The combined timings of the remote
+// children of up to maxSkewSpans unexported parents are remembered until the parent is exported, and
+// the timings of up to maxSkewSpans exported spans are remembered for children that arrive later.
+func NewSkewExporter(next sdktrace.SpanExporter) *SkewExporter {
+	return &SkewExporter{
+		next:     next,
+		children: newTimingCache(maxSkewSpans),
+		parents:  newTimingCache(maxSkewSpans),
+	}
+}
+
+func (e *SkewExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	var out []sdktrace.ReadOnlySpan
+	annotate := func(i int, skew time.Duration) {
+		if out == nil {
+			out = make([]sdktrace.ReadOnlySpan, len(spans))
+			copy(out, spans)
+		}
+		stub := tracetest.SpanStubFromReadOnlySpan(spans[i])
+		stub.Attributes = append(stub.Attributes,
+			attribute.Bool("clock.skew_suspected", true),
+			tracing.DurationAttribute("clock.skew", skew),
+		)
+		out[i] = stub.Snapshot()
+	}
+
+	e.mu.Lock()
+	for i, s := range spans {
+		if !s.Parent().IsValid() || !s.Parent().IsRemote() {
+			continue
+		}
+		child := spanTimes{start: s.StartTime(), end: s.EndTime()}
+		if pt, ok := e.parents.get(s.Parent().SpanID()); ok {
+			if skew := skewOf(pt, child); skew > 0 {
+				annotate(i, skew)
+			}
+			continue
+		}
+		e.children.merge(s.Parent().SpanID(), child)
+	}
+
+	for i, s := range spans {
+		id := s.SpanContext().SpanID()
+		pt := spanTimes{start: s.StartTime(), end: s.EndTime()}
+		e.parents.merge(id, pt)
+
+		ct, ok := e.children.get(id)
+		if !ok {
+			continue
+		}
+		e.children.remove(id)
+		if skew := skewOf(pt, ct); skew > 0 {
+			annotate(i, skew)
+		}
+	}
+	e.mu.Unlock()
+
+	if out == nil {
+		out = spans
+	}
+	return e.next.ExportSpans(ctx, out)
+}
+
+func (e *SkewExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// spanTimes is the start and end of a span, or the earliest start and latest end of a group of spans
+type spanTimes struct {
+	start time.Time
+	end   time.Time
+}
+
+// timingCache remembers the timings of a bounded number of spans, evicting the oldest when full
+type timingCache struct {
+	max     int
+	entries map[trace.SpanID]spanTimes
+	order   []trace.SpanID
+}
+
+func newTimingCache(max int) *timingCache {
+	return &timingCache{max: max, entries: make(map[trace.SpanID]spanTimes)}
+}
+
+func (c *timingCache) get(id trace.SpanID) (spanTimes, bool) {
+	t, ok := c.entries[id]
+	return t, ok
+}
+
+func (c *timingCache) remove(id trace.SpanID) {
+	delete(c.entries, id)
+}
+
+// merge records the timing for id, widening any timing already recorded so that it covers both
+func (c *timingCache) merge(id trace.SpanID, t spanTimes) {
+	cur, ok := c.entries[id]
+	if !ok {
+		if len(c.order) >= c.max {
+			c.compact()
+		}
+		c.entries[id] = t
+		c.order = append(c.order, id)
+		return
+	}
+	if t.start.Before(cur.start) {
+		cur.start = t.start
+	}
+	if t.end.After(cur.end) {
+		cur.end = t.end
+	}
+	c.entries[id] = cur
+}
+
+// compact drops removed entries from the eviction order and then evicts the oldest remaining entries
+// until there is room for another
+func (c *timingCache) compact() {
+	order := c.order[:0]
+	for _, id := range c.order {
+		if _, ok := c.entries[id]; ok {
+			order = append(order, id)
+		}
+	}
+	for len(order) >= c.max {
+		delete(c.entries, order[0])
+		order = order[1:]
+	}
+	c.order = append(c.order[:0], order...)
+}
+
+// skewOf returns the largest amount by which the children's timings fall outside their parent's
+func skewOf(parent spanTimes, children spanTimes) time.Duration {
+	var skew time.Duration
+	if d := parent.start.Sub(children.start); d > skew {
+		skew = d
+	}
+	if d := children.end.Sub(parent.end); d > skew {
+		skew = d
+	}
+	return skew
+}
@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSkewExporter(t *testing.T) {
+	base := time.Unix(1000, 0)
+	traceID := trace.TraceID{1}
+	spanContext := func(id byte, remote bool) trace.SpanContext {
+		return trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{id}, Remote: remote})
+	}
+	parent := tracetest.SpanStub{
+		Name:        "Gateway.Fetch",
+		SpanContext: spanContext(1, false),
+		StartTime:   base,
+		EndTime:     base.Add(100 * time.Millisecond),
+	}.Snapshot()
+	child := func(startOffset time.Duration) sdktrace.ReadOnlySpan {
+		return tracetest.SpanStub{
+			Name:        "Bitswap.Serve",
+			SpanContext: spanContext(2, false),
+			Parent:      spanContext(1, true),
+			StartTime:   base.Add(startOffset),
+			EndTime:     base.Add(startOffset + 10*time.Millisecond),
+		}.Snapshot()
+	}
+
+	testCases := []struct {
+		name      string
+		batches   [][]sdktrace.ReadOnlySpan
+		annotated string
+	}{
+		{
+			name:      "child in an earlier batch",
+			batches:   [][]sdktrace.ReadOnlySpan{{child(-40 * time.Millisecond)}, {parent}},
+			annotated: "Gateway.Fetch",
+		},
+		{
+			name:      "child in the same batch",
+			batches:   [][]sdktrace.ReadOnlySpan{{parent, child(-40 * time.Millisecond)}},
+			annotated: "Gateway.Fetch",
+		},
+		{
+			name:      "child in a later batch",
+			batches:   [][]sdktrace.ReadOnlySpan{{parent}, {child(-40 * time.Millisecond)}},
+			annotated: "Bitswap.Serve",
+		},
+		{
+			name:    "child within its parent",
+			batches: [][]sdktrace.ReadOnlySpan{{parent}, {child(20 * time.Millisecond)}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := tracetest.NewInMemoryExporter()
+			e := NewSkewExporter(next)
+			for _, batch := range tc.batches {
+				if err := e.ExportSpans(context.Background(), batch); err != nil {
+					t.Fatalf("export: %v", err)
+				}
+			}
+
+			spans := next.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("got %d spans, want 2", len(spans))
+			}
+			for _, s := range spans {
+				attrs := map[attribute.Key]attribute.Value{}
+				for _, kv := range s.Attributes {
+					attrs[kv.Key] = kv.Value
+				}
+				_, suspected := attrs["clock.skew_suspected"]
+				if want := s.Name == tc.annotated; suspected != want {
+					t.Errorf("%s: got skew suspected %v, want %v", s.Name, suspected, want)
+				}
+				if suspected && attrs["clock.skew_ms"].AsInt64() != 40 {
+					t.Errorf("%s: got clock.skew_ms %d, want 40", s.Name, attrs["clock.skew_ms"].AsInt64())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package processor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxSLOExemplars is the number of trace ids of recent violations kept for each objective
+const maxSLOExemplars = 10
+
+// SLO is a service level objective evaluated against the local root spans with a given name
+type SLO struct {
+	// Name identifies the objective in reported statistics
+	Name string
+
+	// SpanName is the name of the root spans the objective applies to, such as "Gateway.ServeHTTP"
+	SpanName string
+
+	// Threshold is the latency a span must not exceed to count as good
+	Threshold time.Duration
+
+	// Key optionally names an integer span attribute holding the latency in milliseconds, such as a
+	// time to first byte. When empty the duration of the span is used. Spans without the attribute are
+	// not evaluated.
+	Key attribute.Key
+
+	// Objective is the fraction of spans, between 0 and 1, that are expected to be good, such as 0.99
+	Objective float64
+}
+
+// SLOStats holds the statistics for an objective since the processor was created
+type SLOStats struct {
+	Name       string
+	Total      int64
+	Violations int64
+
+	// BurnRate is the rate at which the error budget is being consumed. A burn rate of one consumes
+	// exactly the budget allowed by the objective, higher rates exhaust it early.
+	BurnRate float64
+
+	// Exemplars holds the trace ids of the most recent violations, newest last
+	Exemplars []trace.TraceID
+}
+
+// SLOProcessor is a span processor that evaluates local root spans against service level objectives
+// so that operators can alert on latency directly from tracing data. Statistics are cumulative and are
+// read using Stats, typically to be published as metrics from which rates over alerting windows can be
+// derived. The processor does not pass spans on and should be registered alongside the exporting
+// processor.
+type SLOProcessor struct {
+	mu    sync.Mutex
+	slos  map[string][]*sloState
+	order []*sloState
+}
+
+type sloState struct {
+	slo        SLO
+	total      int64
+	violations int64
+	exemplars  []trace.TraceID
+}
+
+var _ sdktrace.SpanProcessor = (*SLOProcessor)(nil)
+
+// NewSLOProcessor creates an SLOProcessor that evaluates the given objectives
+func NewSLOProcessor(slos ...SLO) *SLOProcessor {
+	p := &SLOProcessor{
+		slos: make(map[string][]*sloState, len(slos)),
+	}
+	for _, slo := range slos {
+		st := &sloState{slo: slo}
+		p.slos[slo.SpanName] = append(p.slos[slo.SpanName], st)
+		p.order = append(p.order, st)
+	}
+	return p
+}
+
+func (p *SLOProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+func (p *SLOProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.Parent().IsValid() && !s.Parent().IsRemote() {
+		return
+	}
+	states := p.slos[s.Name()]
+	if len(states) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, st := range states {
+		latency, ok := st.latency(s)
+		if !ok {
+			continue
+		}
+		st.total++
+		if latency <= st.slo.Threshold {
+			continue
+		}
+		st.violations++
+		if len(st.exemplars) >= maxSLOExemplars {
+			st.exemplars = append(st.exemplars[:0], st.exemplars[1:]...)
+		}
+		st.exemplars = append(st.exemplars, s.SpanContext().TraceID())
+	}
+}
+
+func (p *SLOProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (p *SLOProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Stats returns the statistics for each objective in the order they were passed to NewSLOProcessor
+func (p *SLOProcessor) Stats() []SLOStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]SLOStats, len(p.order))
+	for i, st := range p.order {
+		stats[i] = SLOStats{
+			Name:       st.slo.Name,
+			Total:      st.total,
+			Violations: st.violations,
+			BurnRate:   st.burnRate(),
+			Exemplars:  append([]trace.TraceID(nil), st.exemplars...),
+		}
+	}
+	return stats
+}
+
+func (st *sloState) latency(s sdktrace.ReadOnlySpan) (time.Duration, bool) {
+	if st.slo.Key == "" {
+		return s.EndTime().Sub(s.StartTime()), true
+	}
+	for _, kv := range s.Attributes() {
+		if kv.Key == st.slo.Key && kv.Value.Type() == attribute.INT64 {
+			return time.Duration(kv.Value.AsInt64()) * time.Millisecond, true
+		}
+	}
+	return 0, false
+}
+
+func (st *sloState) burnRate() float64 {
+	if st.total == 0 {
+		return 0
+	}
+	budget := 1 - st.slo.Objective
+	if budget <= 0 {
+		if st.violations > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return float64(st.violations) / float64(st.total) / budget
+}
@@ -0,0 +1,164 @@
+package processor
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTrackedParents is the number of open parent spans a TopChildren processor tracks at once. Children
+// of further parents are passed through unchanged.
+const maxTrackedParents = 10000
+
+// TopChildren is a span processor that keeps only the slowest direct children of spans with configured
+// names, such as the per-block spans of a large DAG traversal, so that traces of huge operations stay
+// readable while keeping the interesting outliers. The remaining children are dropped and summarized on
+// the parent span as children.dropped_count and children.dropped_ms, the total duration of the dropped
+// children. Kept children are held until the parent ends and are exported with it. Children ending
+// after their parent and the descendants of children are passed through unchanged, so the descendants
+// of a dropped child lose their parent.
+type TopChildren struct {
+	next  sdktrace.SpanProcessor
+	n     int
+	names map[string]bool
+
+	mu       sync.Mutex
+	parents  map[trace.SpanID]*childSet
+	children map[trace.SpanID]trace.SpanID
+}
+
+var _ sdktrace.SpanProcessor = (*TopChildren)(nil)
+
+// NewTopChildren creates a TopChildren processor that passes spans to next, keeping the n slowest
+// children of spans with any of the given names
+func NewTopChildren(next sdktrace.SpanProcessor, n int, parentNames ...string) *TopChildren {
+	p := &TopChildren{
+		next:     next,
+		n:        n,
+		names:    make(map[string]bool, len(parentNames)),
+		parents:  make(map[trace.SpanID]*childSet),
+		children: make(map[trace.SpanID]trace.SpanID),
+	}
+	for _, name := range parentNames {
+		p.names[name] = true
+	}
+	return p
+}
+
+func (p *TopChildren) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+
+	// The span held by the parent context may be wrapped by the tracing package, so parents are
+	// identified from their own start rather than from the context of their children.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if parentID := s.Parent().SpanID(); s.Parent().IsValid() {
+		if _, tracked := p.parents[parentID]; tracked {
+			p.children[s.SpanContext().SpanID()] = parentID
+		}
+	}
+	if p.names[s.Name()] && len(p.parents) < maxTrackedParents {
+		p.parents[s.SpanContext().SpanID()] = &childSet{}
+	}
+}
+
+func (p *TopChildren) OnEnd(s sdktrace.ReadOnlySpan) {
+	id := s.SpanContext().SpanID()
+
+	p.mu.Lock()
+	set, isParent := p.parents[id]
+	delete(p.parents, id)
+	parentID, isChild := p.children[id]
+	delete(p.children, id)
+	if isChild && !isParent {
+		if parentSet, ok := p.parents[parentID]; ok {
+			parentSet.add(s, p.n)
+			p.mu.Unlock()
+			return
+		}
+	}
+	p.mu.Unlock()
+
+	if isParent {
+		for _, child := range set.kept {
+			p.next.OnEnd(child)
+		}
+		if set.dropped > 0 {
+			stub := tracetest.SpanStubFromReadOnlySpan(s)
+			stub.Attributes = append(stub.Attributes,
+				attribute.Int("children.dropped_count", set.dropped),
+				attribute.Int64("children.dropped_ms", set.droppedDuration.Milliseconds()),
+			)
+			s = stub.Snapshot()
+		}
+	}
+
+	// a parent that is itself the child of a tracked parent competes with its siblings
+	if isChild && isParent {
+		p.mu.Lock()
+		if parentSet, ok := p.parents[parentID]; ok {
+			parentSet.add(s, p.n)
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+	}
+	p.next.OnEnd(s)
+}
+
+func (p *TopChildren) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	parents := p.parents
+	p.parents = make(map[trace.SpanID]*childSet)
+	p.children = make(map[trace.SpanID]trace.SpanID)
+	p.mu.Unlock()
+
+	for _, set := range parents {
+		for _, child := range set.kept {
+			p.next.OnEnd(child)
+		}
+	}
+	return p.next.Shutdown(ctx)
+}
+
+func (p *TopChildren) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// childSet holds the slowest children of a parent as a min-heap ordered by duration, along with a
+// summary of the children that have been dropped
+type childSet struct {
+	kept            []sdktrace.ReadOnlySpan
+	dropped         int
+	droppedDuration time.Duration
+}
+
+func (c *childSet) add(s sdktrace.ReadOnlySpan, n int) {
+	heap.Push(c, s)
+	if c.Len() > n {
+		dropped := heap.Pop(c).(sdktrace.ReadOnlySpan)
+		c.dropped++
+		c.droppedDuration += duration(dropped)
+	}
+}
+
+func (c *childSet) Len() int           { return len(c.kept) }
+func (c *childSet) Less(i, j int) bool { return duration(c.kept[i]) < duration(c.kept[j]) }
+func (c *childSet) Swap(i, j int)      { c.kept[i], c.kept[j] = c.kept[j], c.kept[i] }
+func (c *childSet) Push(x interface{}) { c.kept = append(c.kept, x.(sdktrace.ReadOnlySpan)) }
+
+func (c *childSet) Pop() interface{} {
+	last := c.kept[len(c.kept)-1]
+	c.kept = c.kept[:len(c.kept)-1]
+	return last
+}
+
+func duration(s sdktrace.ReadOnlySpan) time.Duration {
+	return s.EndTime().Sub(s.StartTime())
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// wrappedSpan stands in for the wrappers added by the tracing package, such as the span registry
+type wrappedSpan struct {
+	trace.Span
+}
+
+func TestTopChildrenWithWrappedParent(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(NewTopChildren(rec, 2, "Test.Traverse")),
+	)
+	tracer := tp.Tracer("")
+
+	// the span held by a child's parent context is a wrapper, not the SDK's span
+	start := time.Now()
+	_, sdkParent := tracer.Start(context.Background(), "Test.Traverse", trace.WithTimestamp(start))
+	parent := wrappedSpan{Span: sdkParent}
+	ctx := trace.ContextWithSpan(context.Background(), parent)
+	for _, ms := range []int{5, 50, 10, 40, 20} {
+		_, child := tracer.Start(ctx, "Test.Visit", trace.WithTimestamp(start))
+		child.End(trace.WithTimestamp(start.Add(time.Duration(ms) * time.Millisecond)))
+	}
+	parent.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	ended := rec.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want the parent and its 2 slowest children", len(ended))
+	}
+
+	var kept []time.Duration
+	var summary map[string]int64
+	for _, s := range ended {
+		switch s.Name() {
+		case "Test.Visit":
+			kept = append(kept, s.EndTime().Sub(s.StartTime()))
+		case "Test.Traverse":
+			summary = map[string]int64{}
+			for _, kv := range s.Attributes() {
+				summary[string(kv.Key)] = kv.Value.AsInt64()
+			}
+		}
+	}
+	for _, d := range kept {
+		if d != 40*time.Millisecond && d != 50*time.Millisecond {
+			t.Errorf("kept a child lasting %v, want only the 40ms and 50ms children", d)
+		}
+	}
+	if summary == nil {
+		t.Fatal("parent span not exported")
+	}
+	if summary["children.dropped_count"] != 3 || summary["children.dropped_ms"] != 35 {
+		t.Errorf("got dropped count %d and duration %dms, want 3 and 35ms", summary["children.dropped_count"], summary["children.dropped_ms"])
+	}
+}
+
+func TestTopChildrenPassesThroughUntrackedSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(NewTopChildren(rec, 1, "Test.Traverse")),
+	)
+	tracer := tp.Tracer("")
+
+	ctx, parent := tracer.Start(context.Background(), "Test.Other")
+	for i := 0; i < 3; i++ {
+		_, child := tracer.Start(ctx, "Test.Visit")
+		child.End()
+	}
+	parent.End()
+
+	if got := len(rec.Ended()); got != 4 {
+		t.Errorf("got %d spans, want all 4", got)
+	}
+}
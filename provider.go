@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// AddProvideEnqueueEvent records an event on the span noting that a cid was added to the provide
+// queue, together with the depth of the queue at the time it was enqueued
+func AddProvideEnqueueEvent(span trace.Span, c cid.Cid, depth int) {
+	if span.IsRecording() {
+		span.AddEvent("provide enqueued", trace.WithAttributes(CidAttribute(c), ProvideQueueDepthAttribute(depth)))
+	}
+}
+
+// SpanWithProvideBatchAttributes is a helper function to assist the common pattern of starting a new
+// span for a batch of provides taken from the provide queue. The depth is the number of cids remaining
+// in the queue and enqueued is the time the oldest cid in the batch was added to the queue.
+func SpanWithProvideBatchAttributes(ctx context.Context, componentName string, spanName string, batchSize int, depth int, enqueued time.Time) (context.Context, trace.Span) {
+	ctx, span := Span(ctx, componentName, spanName)
+	if span.IsRecording() {
+		span.SetAttributes(
+			ProvideBatchSizeAttribute(batchSize),
+			ProvideQueueDepthAttribute(depth),
+			ProvideQueueWaitAttribute(since(enqueued)),
+		)
+	}
+	return ctx, span
+}
+
+// ProvideQueueDepthAttribute creates a span attribute with a standard name for representing the
+// number of cids waiting in the provide queue
+func ProvideQueueDepthAttribute(depth int) attribute.KeyValue {
+	return attribute.Int("provide.queue.depth", depth)
+}
+
+// ProvideQueueWaitAttribute creates a span attribute with a standard name for representing the time
+// a cid spent in the provide queue before being provided
+func ProvideQueueWaitAttribute(d time.Duration) attribute.KeyValue {
+	return DurationAttribute("provide.queue.wait", d)
+}
+
+// ProvideBatchSizeAttribute creates a span attribute with a standard name for representing the number
+// of cids provided in a single batch
+func ProvideBatchSizeAttribute(n int) attribute.KeyValue {
+	return attribute.Int("provide.batch.count", n)
+}
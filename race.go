@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errNoAttempts = errors.New("tracing: no attempts to race")
+
+// Attempt is one of the concurrent strategies run by Race
+type Attempt[T any] struct {
+	// Name identifies the attempt in traces, for example the gateway or exchange it fetches from
+	Name string
+
+	// Fn performs the attempt. It must return promptly when its context is cancelled.
+	Fn func(ctx context.Context) (T, error)
+}
+
+// Race runs the attempts concurrently under a single span and returns the result of the first to
+// succeed, cancelling the others. Each attempt runs in its own child span named after the race span
+// with an Attempt suffix and linked to the race span, so the attempts can be found from the race in
+// backends that do not show the full tree. The race span records the name of the winning attempt and
+// each attempt span records its outcome: won, lost if it succeeded after another attempt had won,
+// cancelled if it failed after another attempt had won, which is usually because the win cancelled
+// it, or failed otherwise. If every attempt fails the error from the last one to fail is returned.
+func Race[T any](ctx context.Context, componentName string, spanName string, attempts ...Attempt[T]) (T, error) {
+	ctx, span := SpanWithIntAttribute(ctx, componentName, spanName, "race.attempts.count", len(attempts))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+		won bool
+	}
+	results := make(chan result, len(attempts))
+	winner := int32(-1)
+
+	for i, a := range attempts {
+		go func(i int, a Attempt[T]) {
+			actx, aspan := Span(ctx, componentName, spanName+"Attempt",
+				trace.WithAttributes(attribute.String("race.attempt", a.Name)),
+				trace.WithLinks(trace.Link{SpanContext: span.SpanContext()}))
+			v, err := a.Fn(actx)
+
+			won := false
+			switch {
+			case err == nil && atomic.CompareAndSwapInt32(&winner, -1, int32(i)):
+				won = true
+				cancel()
+				aspan.SetAttributes(attribute.String("race.outcome", "won"))
+			case err == nil:
+				aspan.SetAttributes(attribute.String("race.outcome", "lost"))
+			case atomic.LoadInt32(&winner) != -1:
+				aspan.SetAttributes(attribute.String("race.outcome", "cancelled"))
+			default:
+				aspan.SetAttributes(attribute.String("race.outcome", "failed"))
+				aspan.RecordError(err)
+				aspan.SetStatus(codes.Error, err.Error())
+			}
+			aspan.End()
+			results <- result{v: v, err: err, won: won}
+		}(i, a)
+	}
+
+	var lastErr error
+	for range attempts {
+		r := <-results
+		if r.won {
+			span.SetAttributes(attribute.String("race.winner", attempts[atomic.LoadInt32(&winner)].Name))
+			return r.v, nil
+		}
+		if r.err != nil {
+			lastErr = r.err
+		}
+	}
+
+	var zero T
+	if lastErr == nil {
+		lastErr = errNoAttempts
+	}
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+	return zero, lastErr
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRaceLinksAttemptsAndRecordsOutcomes(t *testing.T) {
+	rec := withRecorder(t)
+
+	v, err := Race(context.Background(), "Test", "Fetch",
+		Attempt[int]{Name: "fast", Fn: func(ctx context.Context) (int, error) { return 1, nil }},
+		Attempt[int]{Name: "slow", Fn: func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}},
+	)
+	if err != nil || v != 1 {
+		t.Fatalf("got %d, %v, want 1, nil", v, err)
+	}
+
+	// the losing attempt ends after Race returns, once it sees the cancellation
+	var spans []sdktrace.ReadOnlySpan
+	deadline := time.Now().Add(5 * time.Second)
+	for spans = rec.Ended(); len(spans) < 3; spans = rec.Ended() {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d ended spans, want 3", len(spans))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var race sdktrace.ReadOnlySpan
+	attempts := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		switch s.Name() {
+		case "Test.Fetch":
+			race = s
+		case "Test.FetchAttempt":
+			attempts[attributeMap(s.Attributes())["race.attempt"]] = s
+		}
+	}
+	if race == nil {
+		t.Fatal("race span not recorded")
+	}
+	if got := attributeMap(race.Attributes())["race.winner"]; got != "fast" {
+		t.Errorf("race.winner: got %q, want fast", got)
+	}
+
+	want := map[string]string{"fast": "won", "slow": "cancelled"}
+	for name, outcome := range want {
+		s, ok := attempts[name]
+		if !ok {
+			t.Errorf("attempt %s not recorded", name)
+			continue
+		}
+		if got := attributeMap(s.Attributes())["race.outcome"]; got != outcome {
+			t.Errorf("attempt %s outcome: got %q, want %q", name, got, outcome)
+		}
+		if s.Parent().SpanID() != race.SpanContext().SpanID() {
+			t.Errorf("attempt %s is not a child of the race span", name)
+		}
+		links := s.Links()
+		if len(links) != 1 || links[0].SpanContext.SpanID() != race.SpanContext().SpanID() {
+			t.Errorf("attempt %s links: got %v, want a link to the race span", name, links)
+		}
+	}
+}
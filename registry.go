@@ -0,0 +1,254 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanInfo describes a span started by this package that has not yet ended
+type SpanInfo struct {
+	Component   string
+	Name        string
+	Start       time.Time
+	SpanContext trace.SpanContext
+	Attributes  []attribute.KeyValue
+}
+
+var (
+	registryEnabled int32
+	registry        = &spanRegistry{spans: map[*registeredSpan]struct{}{}}
+)
+
+// EnableSpanRegistry controls whether spans started by this package are tracked while they are open
+// so they can be listed by OpenSpans. Only recording spans are tracked. The registry is disabled by
+// default.
+func EnableSpanRegistry(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&registryEnabled, v)
+}
+
+// OpenSpans returns information about the spans started by this package that are currently open,
+// ordered by start time. It returns nothing unless the registry has been enabled using
+// EnableSpanRegistry.
+func OpenSpans() []SpanInfo {
+	return registry.list()
+}
+
+// OpenSpansHandler returns an http handler that writes the currently open spans as JSON, intended
+// to be mounted on a node's debug endpoint
+func OpenSpansHandler() http.Handler {
+	type jsonSpan struct {
+		Component  string            `json:"component"`
+		Name       string            `json:"name"`
+		Start      time.Time         `json:"start"`
+		TraceID    string            `json:"trace_id"`
+		SpanID     string            `json:"span_id"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		infos := OpenSpans()
+		out := make([]jsonSpan, 0, len(infos))
+		for _, info := range infos {
+			js := jsonSpan{
+				Component: info.Component,
+				Name:      info.Name,
+				Start:     info.Start,
+				TraceID:   info.SpanContext.TraceID().String(),
+				SpanID:    info.SpanContext.SpanID().String(),
+			}
+			if len(info.Attributes) > 0 {
+				js.Attributes = make(map[string]string, len(info.Attributes))
+				for _, kv := range info.Attributes {
+					js.Attributes[string(kv.Key)] = kv.Value.Emit()
+				}
+			}
+			out = append(out, js)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(out)
+	})
+}
+
+// StartSpanJanitor starts a background sweep of the span registry that runs every interval and
+// force-ends any open span started more than maxAge ago. Abandoned spans are marked with an
+// attribute and an error status so incomplete traces can be identified. The janitor stops when
+// the context is cancelled.
+func StartSpanJanitor(ctx context.Context, maxAge time.Duration, interval time.Duration) {
+	go func() {
+		for {
+			tick, stop := after(interval)
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-tick:
+				for _, rs := range registry.olderThan(now().Add(-maxAge)) {
+					s := rs.outermost()
+					s.SetAttributes(attribute.Bool("abandoned", true))
+					s.SetStatus(codes.Error, "abandoned")
+					s.End()
+				}
+			}
+		}
+	}()
+}
+
+func spanRegistryEnabled() bool {
+	return atomic.LoadInt32(&registryEnabled) == 1
+}
+
+// registerSpan wraps the span so that it is tracked by the registry until it is ended
+func registerSpan(ctx context.Context, span trace.Span, componentName string, spanName string, opts []trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	rs := &registeredSpan{
+		Span:      span,
+		component: componentName,
+		name:      spanName,
+		start:     now(),
+		attrs:     cfg.Attributes(),
+	}
+	registry.add(rs)
+	return trace.ContextWithSpan(ctx, rs), rs
+}
+
+// spanWrapper is implemented by the spans of this package that wrap another span after it has been
+// started, such as heartbeat and timeout spans
+type spanWrapper interface {
+	unwrap() trace.Span
+}
+
+// wrapRegistered records outer as the outermost wrapper of the registered span that inner is or wraps,
+// so that a span ended by the registry, such as by the janitor or CrashSpans, is ended through every
+// wrapper. It does nothing if inner does not lead to a registered span.
+func wrapRegistered(inner trace.Span, outer trace.Span) {
+	for {
+		switch s := inner.(type) {
+		case *registeredSpan:
+			s.mu.Lock()
+			s.outer = outer
+			s.mu.Unlock()
+			return
+		case spanWrapper:
+			inner = s.unwrap()
+		default:
+			return
+		}
+	}
+}
+
+type spanRegistry struct {
+	mu    sync.Mutex
+	spans map[*registeredSpan]struct{}
+}
+
+func (r *spanRegistry) add(s *registeredSpan) {
+	r.mu.Lock()
+	r.spans[s] = struct{}{}
+	r.mu.Unlock()
+}
+
+func (r *spanRegistry) remove(s *registeredSpan) {
+	r.mu.Lock()
+	delete(r.spans, s)
+	r.mu.Unlock()
+}
+
+func (r *spanRegistry) all() []*registeredSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]*registeredSpan, 0, len(r.spans))
+	for s := range r.spans {
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+func (r *spanRegistry) olderThan(t time.Time) []*registeredSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var spans []*registeredSpan
+	for s := range r.spans {
+		if s.start.Before(t) {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func (r *spanRegistry) list() []SpanInfo {
+	r.mu.Lock()
+	infos := make([]SpanInfo, 0, len(r.spans))
+	for s := range r.spans {
+		infos = append(infos, s.info())
+	}
+	r.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Start.Before(infos[j].Start) })
+	return infos
+}
+
+type registeredSpan struct {
+	trace.Span
+	component string
+	name      string
+	start     time.Time
+
+	mu    sync.Mutex
+	attrs []attribute.KeyValue
+	outer trace.Span
+}
+
+// outermost returns the outermost wrapper of the span recorded by wrapRegistered, or the span itself
+func (s *registeredSpan) outermost() trace.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outer != nil {
+		return s.outer
+	}
+	return s
+}
+
+func (s *registeredSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	s.attrs = append(s.attrs, kv...)
+	s.mu.Unlock()
+	s.Span.SetAttributes(kv...)
+}
+
+func (s *registeredSpan) End(opts ...trace.SpanEndOption) {
+	registry.remove(s)
+	s.Span.End(opts...)
+}
+
+func (s *registeredSpan) info() SpanInfo {
+	s.mu.Lock()
+	attrs := make([]attribute.KeyValue, len(s.attrs))
+	copy(attrs, s.attrs)
+	s.mu.Unlock()
+
+	return SpanInfo{
+		Component:   s.component,
+		Name:        s.name,
+		Start:       s.start,
+		SpanContext: s.SpanContext(),
+		Attributes:  attrs,
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJanitorEndsWrappedSpans(t *testing.T) {
+	rec := withRecorder(t)
+	EnableSpanRegistry(true)
+	defer EnableSpanRegistry(false)
+
+	ctx, tspan, cancel := WithTimeoutSpan(context.Background(), "Test", "Fetch", time.Millisecond)
+	defer cancel()
+	_, hspan := HeartbeatFor(ctx, tspan, time.Millisecond, time.Millisecond, nil)
+	hs := hspan.(*heartbeatSpan)
+	<-ctx.Done()
+
+	jctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	StartSpanJanitor(jctx, 0, time.Millisecond)
+
+	select {
+	case <-hs.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat not stopped when the janitor ended the span")
+	}
+
+	ended := rec.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d ended spans, want 1", len(ended))
+	}
+	attrs := attributeMap(ended[0].Attributes())
+	if attrs["abandoned"] != "true" {
+		t.Errorf("abandoned: got %q, want true", attrs["abandoned"])
+	}
+	if attrs["timeout.expired"] != "true" {
+		t.Errorf("timeout.expired: got %q, want true", attrs["timeout.expired"])
+	}
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanForLockWait starts a span covering the wait to acquire a repo level lock, such as the repo lock,
+// the GC lock or a pin lock. Callers should end the span once the lock has been acquired. These waits
+// serialize otherwise unrelated requests and would not otherwise appear in any span.
+func SpanForLockWait(ctx context.Context, componentName string, lockName string) (context.Context, trace.Span) {
+	return SpanWithStringAttribute(ctx, componentName, "LockWait", "lock.name", lockName)
+}
+
+// WaitLock acquires the lock, covering the wait with a span started using SpanForLockWait
+func WaitLock(ctx context.Context, componentName string, lockName string, l sync.Locker) {
+	_, span := SpanForLockWait(ctx, componentName, lockName)
+	l.Lock()
+	span.End()
+}
+
+// SpanForSyncBarrier starts a span covering a datastore sync barrier, such as a flatfs directory sync
+// or a badger value log sync, that blocks writers until data is durable. The backend names the
+// datastore and pending is the number of writes waiting on the barrier, if known.
+func SpanForSyncBarrier(ctx context.Context, componentName string, backend string, pending int) (context.Context, trace.Span) {
+	return Span(ctx, componentName, "SyncBarrier", trace.WithAttributes(
+		attribute.String("sync.backend", backend),
+		attribute.Int("sync.pending.count", pending),
+	))
+}
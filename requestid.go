@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// RequestIDHeader is the name of the HTTP response header used to return the request ID to clients
+const RequestIDHeader = "X-Request-Id"
+
+// requestIDKey is the key used for the request ID in baggage and span attributes
+const requestIDKey = "request_id"
+
+// NewRequestID generates a short random identifier suitable for correlating the logs and spans of
+// a single request
+func NewRequestID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// WithRequestID returns a context carrying the request ID in its baggage. Every span started by this
+// package using the returned context will carry the request ID as an attribute.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if m, err := baggage.NewMember(requestIDKey, id); err == nil {
+		if b, err := baggage.FromContext(ctx).SetMember(m); err == nil {
+			ctx = baggage.ContextWithBaggage(ctx, b)
+		}
+	}
+	return WithCommonAttributes(ctx, RequestIDAttribute(id))
+}
+
+// RequestIDFromContext returns the request ID held in the context's baggage or an empty string if there
+// is none
+func RequestIDFromContext(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(requestIDKey).Value()
+}
+
+// RequestIDAttribute creates a span attribute with a standard name for representing a request ID
+func RequestIDAttribute(id string) attribute.KeyValue {
+	return attribute.String(requestIDKey, id)
+}
+
+// RequestIDHandler wraps an http handler so that every request is assigned a request ID at ingress.
+// A request ID already present in the request context's baggage is reused, otherwise a new one is
+// generated. The ID is returned to the client in the RequestIDHeader response header.
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := RequestIDFromContext(ctx)
+		if id == "" {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(ctx, id)))
+	})
+}
@@ -0,0 +1,76 @@
+package sampling
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PeerBudget is a sampler that limits the number of server spans sampled for each remote peer per
+// minute, so that a single aggressive peer cannot consume the whole trace volume. The peer is read
+// from a span start attribute. Spans of other kinds, or without the peer attribute, are left to the
+// base sampler's decision.
+type PeerBudget struct {
+	base   sdktrace.Sampler
+	key    attribute.Key
+	budget int
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int
+}
+
+var _ sdktrace.Sampler = (*PeerBudget)(nil)
+
+// NewPeerBudget creates a PeerBudget that allows each peer, identified by the value of the attribute
+// with the given key, up to perMinute sampled server spans per minute. Decisions for spans within
+// the budget are made by the base sampler.
+func NewPeerBudget(base sdktrace.Sampler, key attribute.Key, perMinute int) *PeerBudget {
+	return &PeerBudget{
+		base:   base,
+		key:    key,
+		budget: perMinute,
+		counts: map[string]int{},
+	}
+}
+
+func (p *PeerBudget) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	res := p.base.ShouldSample(params)
+	if res.Decision != sdktrace.RecordAndSample || params.Kind != trace.SpanKindServer {
+		return res
+	}
+
+	var peer string
+	for _, kv := range params.Attributes {
+		if kv.Key == p.key {
+			peer = kv.Value.Emit()
+			break
+		}
+	}
+	if peer == "" {
+		return res
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	if now.Sub(p.window) >= time.Minute {
+		p.window = now
+		p.counts = map[string]int{}
+	}
+	p.counts[peer]++
+	over := p.counts[peer] > p.budget
+	p.mu.Unlock()
+
+	if over {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: res.Tracestate}
+	}
+	return res
+}
+
+func (p *PeerBudget) Description() string {
+	return fmt.Sprintf("PeerBudget{key:%s,perMinute:%d,base:%s}", p.key, p.budget, p.base.Description())
+}
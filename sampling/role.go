@@ -0,0 +1,61 @@
+package sampling
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NodeRoleKey is the resource attribute key used to describe the role of a node, such as "gateway",
+// "provider" or "personal"
+const NodeRoleKey = attribute.Key("ipfs.node.role")
+
+// NodeRoleAttribute creates a resource attribute describing the role of a node
+func NodeRoleAttribute(role string) attribute.KeyValue {
+	return NodeRoleKey.String(role)
+}
+
+// ResourceSampler is a sampler that delegates to a sampler chosen by the value of a resource attribute,
+// such as the node role, so that a single binary and configuration can ship sampling defaults suited to
+// each kind of node in a heterogeneous fleet. Samplers do not see the resource, so the choice is made
+// once when the ResourceSampler is created from the resource passed to the tracer provider.
+type ResourceSampler struct {
+	key      attribute.Key
+	value    string
+	delegate sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*ResourceSampler)(nil)
+
+// NewResourceSampler creates a ResourceSampler that uses the sampler mapped to the value of the
+// resource attribute with the given key. The fallback sampler is used when the resource has no such
+// attribute or its value is not mapped.
+func NewResourceSampler(res *resource.Resource, key attribute.Key, samplers map[string]sdktrace.Sampler, fallback sdktrace.Sampler) *ResourceSampler {
+	s := &ResourceSampler{
+		key:      key,
+		delegate: fallback,
+	}
+	if v, ok := res.Set().Value(key); ok {
+		s.value = v.Emit()
+		if sampler, ok := samplers[s.value]; ok {
+			s.delegate = sampler
+		}
+	}
+	return s
+}
+
+// NewRoleSampler creates a ResourceSampler that chooses a sampler by the node role held in the
+// resource under NodeRoleKey
+func NewRoleSampler(res *resource.Resource, samplers map[string]sdktrace.Sampler, fallback sdktrace.Sampler) *ResourceSampler {
+	return NewResourceSampler(res, NodeRoleKey, samplers, fallback)
+}
+
+func (s *ResourceSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return s.delegate.ShouldSample(params)
+}
+
+func (s *ResourceSampler) Description() string {
+	return fmt.Sprintf("ResourceSampler{key:%s,value:%s,delegate:%s}", s.key, s.value, s.delegate.Description())
+}
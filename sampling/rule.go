@@ -0,0 +1,49 @@
+package sampling
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RuleKey is the attribute key used to record the name of the sampling rule that sampled a span
+const RuleKey = attribute.Key("sampling.rule")
+
+// Rule is a sampler that annotates the local root spans it samples with the name of the rule, so that
+// operators tuning a sampling configuration can verify from the traces which rule applied. Rules may
+// be nested, such as a component ratio rule within a peer budget rule, in which case the innermost
+// rule that sampled the span is recorded.
+type Rule struct {
+	name    string
+	sampler sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*Rule)(nil)
+
+// NewRule creates a Rule with the given name that delegates sampling decisions to the sampler
+func NewRule(name string, sampler sdktrace.Sampler) *Rule {
+	return &Rule{name: name, sampler: sampler}
+}
+
+func (r *Rule) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	res := r.sampler.ShouldSample(params)
+	if res.Decision != sdktrace.RecordAndSample {
+		return res
+	}
+	if psc := trace.SpanContextFromContext(params.ParentContext); psc.IsValid() && !psc.IsRemote() {
+		return res
+	}
+	for _, kv := range res.Attributes {
+		if kv.Key == RuleKey {
+			return res
+		}
+	}
+	res.Attributes = append(res.Attributes[:len(res.Attributes):len(res.Attributes)], RuleKey.String(r.name))
+	return res
+}
+
+func (r *Rule) Description() string {
+	return fmt.Sprintf("Rule{name:%s,sampler:%s}", r.name, r.sampler.Description())
+}
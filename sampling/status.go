@@ -0,0 +1,215 @@
+// Package sampling provides OpenTelemetry samplers and sampling span processors tuned for IPFS nodes.
+package sampling
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBufferedTraces is the number of undecided traces a StatusTailSampler holds before it stops
+// buffering and passes spans of new traces straight through
+const maxBufferedTraces = 10000
+
+// maxSpansPerTrace is the number of spans a StatusTailSampler buffers for a single trace. When a trace
+// exceeds it the trace is kept, its buffered spans are passed on and its later spans pass straight
+// through, so a long lived local root with many children cannot grow the buffer without limit.
+const maxSpansPerTrace = 1000
+
+// StatusTailSampler is a span processor that buffers the spans of each trace until the local root
+// span ends and then decides whether to pass the trace to the next processor based on the root's
+// HTTP response status. Traces whose root ended with a 5xx or 429 status are always kept, other
+// HTTP traces are kept with a configured probability and traces whose root carries no HTTP status
+// are always kept. The tracer provider should be configured to sample every span so the tail
+// sampler sees complete traces.
+type StatusTailSampler struct {
+	next      sdktrace.SpanProcessor
+	threshold uint64
+
+	mu         sync.Mutex
+	traces     map[trace.TraceID][]sdktrace.ReadOnlySpan
+	decided    *traceCache
+	overflowed *traceCache
+}
+
+var _ sdktrace.SpanProcessor = (*StatusTailSampler)(nil)
+
+// NewStatusTailSampler creates a StatusTailSampler that passes kept traces to next. The fraction is
+// the probability, between 0 and 1, that a trace whose root did not end with an error status is kept.
+func NewStatusTailSampler(next sdktrace.SpanProcessor, fraction float64) *StatusTailSampler {
+	return &StatusTailSampler{
+		next:       next,
+		threshold:  ratioThreshold(fraction),
+		traces:     map[trace.TraceID][]sdktrace.ReadOnlySpan{},
+		decided:    newTraceCache(maxBufferedTraces),
+		overflowed: newTraceCache(maxBufferedTraces),
+	}
+}
+
+func (p *StatusTailSampler) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *StatusTailSampler) OnEnd(s sdktrace.ReadOnlySpan) {
+	traceID := s.SpanContext().TraceID()
+	isRoot := !s.Parent().IsValid() || s.Parent().IsRemote()
+
+	p.mu.Lock()
+	if keep, ok := p.decided.get(traceID); ok && !isRoot {
+		// span ended after its local root
+		p.mu.Unlock()
+		if keep {
+			p.next.OnEnd(s)
+		}
+		return
+	}
+
+	if _, ok := p.overflowed.get(traceID); ok {
+		// trace was kept when it exceeded maxSpansPerTrace
+		if isRoot {
+			p.overflowed.remove(traceID)
+		}
+		p.mu.Unlock()
+		p.next.OnEnd(s)
+		return
+	}
+
+	if !isRoot {
+		buffered, buffering := p.traces[traceID]
+		if !buffering && len(p.traces) >= maxBufferedTraces {
+			p.mu.Unlock()
+			p.next.OnEnd(s)
+			return
+		}
+		if len(buffered) < maxSpansPerTrace {
+			p.traces[traceID] = append(buffered, s)
+			p.mu.Unlock()
+			return
+		}
+
+		delete(p.traces, traceID)
+		p.overflowed.put(traceID, true)
+		p.mu.Unlock()
+		for _, span := range buffered {
+			p.next.OnEnd(span)
+		}
+		p.next.OnEnd(s)
+		return
+	}
+
+	spans := append(p.traces[traceID], s)
+	delete(p.traces, traceID)
+	keep := p.keep(s)
+	p.decided.put(traceID, keep)
+	p.mu.Unlock()
+
+	if keep {
+		for _, span := range spans {
+			p.next.OnEnd(span)
+		}
+	}
+}
+
+func (p *StatusTailSampler) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	traces := p.traces
+	p.traces = map[trace.TraceID][]sdktrace.ReadOnlySpan{}
+	p.mu.Unlock()
+
+	// traces that never completed are kept since they may hold the only record of a failure
+	for _, spans := range traces {
+		for _, span := range spans {
+			p.next.OnEnd(span)
+		}
+	}
+	return p.next.Shutdown(ctx)
+}
+
+func (p *StatusTailSampler) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *StatusTailSampler) keep(root sdktrace.ReadOnlySpan) bool {
+	for _, kv := range root.Attributes() {
+		if kv.Key != semconv.HTTPStatusCodeKey {
+			continue
+		}
+		status := kv.Value.AsInt64()
+		if status >= 500 || status == 429 {
+			return true
+		}
+		return traceIDBelow(root.SpanContext().TraceID(), p.threshold)
+	}
+	return true
+}
+
+// traceCache holds a bounded number of per-trace decisions. When it is full the oldest decision is
+// evicted, so traces still in flight keep their decisions while the cache turns over.
+type traceCache struct {
+	max     int
+	order   *list.List
+	entries map[trace.TraceID]*list.Element
+}
+
+type traceCacheEntry struct {
+	traceID trace.TraceID
+	value   bool
+}
+
+func newTraceCache(max int) *traceCache {
+	return &traceCache{
+		max:     max,
+		order:   list.New(),
+		entries: map[trace.TraceID]*list.Element{},
+	}
+}
+
+func (c *traceCache) get(traceID trace.TraceID) (bool, bool) {
+	e, ok := c.entries[traceID]
+	if !ok {
+		return false, false
+	}
+	return e.Value.(*traceCacheEntry).value, true
+}
+
+func (c *traceCache) put(traceID trace.TraceID, value bool) {
+	if e, ok := c.entries[traceID]; ok {
+		e.Value.(*traceCacheEntry).value = value
+		return
+	}
+	if c.order.Len() >= c.max {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*traceCacheEntry).traceID)
+	}
+	c.entries[traceID] = c.order.PushBack(&traceCacheEntry{traceID: traceID, value: value})
+}
+
+func (c *traceCache) remove(traceID trace.TraceID) {
+	if e, ok := c.entries[traceID]; ok {
+		c.order.Remove(e)
+		delete(c.entries, traceID)
+	}
+}
+
+// ratioThreshold converts a sampling probability into a threshold comparable with the value
+// derived from a trace id by traceIDBelow, using the same scheme as the SDK's TraceIDRatioBased
+// sampler so decisions are consistent across processes
+func ratioThreshold(fraction float64) uint64 {
+	if fraction >= 1 {
+		return 1 << 63
+	}
+	if fraction <= 0 {
+		return 0
+	}
+	return uint64(fraction * (1 << 63))
+}
+
+func traceIDBelow(id trace.TraceID, threshold uint64) bool {
+	return binary.BigEndian.Uint64(id[0:8])>>1 < threshold
+}
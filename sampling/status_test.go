@@ -0,0 +1,88 @@
+package sampling
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceIDBelowMatchesSDK(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, fraction := range []float64{0, 0.01, 0.3, 0.5, 0.99, 1} {
+		sampler := sdktrace.TraceIDRatioBased(fraction)
+		threshold := ratioThreshold(fraction)
+		for i := 0; i < 1000; i++ {
+			var id trace.TraceID
+			rng.Read(id[:])
+			want := sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: id}).Decision == sdktrace.RecordAndSample
+			if got := traceIDBelow(id, threshold); got != want {
+				t.Fatalf("fraction %v, trace id %s: got %v, SDK decided %v", fraction, id, got, want)
+			}
+		}
+	}
+}
+
+func TestStatusTailSamplerCapsSpansPerTrace(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewStatusTailSampler(rec, 0)))
+	tr := tp.Tracer("")
+
+	ctx, root := tr.Start(context.Background(), "root")
+	for i := 0; i < maxSpansPerTrace+10; i++ {
+		_, child := tr.Start(ctx, "child")
+		child.End()
+	}
+
+	if got := len(rec.Ended()); got != maxSpansPerTrace+10 {
+		t.Errorf("got %d spans passed on before the root ended, want %d", got, maxSpansPerTrace+10)
+	}
+
+	root.End()
+	if got := len(rec.Ended()); got != maxSpansPerTrace+11 {
+		t.Errorf("got %d spans passed on after the root ended, want %d", got, maxSpansPerTrace+11)
+	}
+}
+
+func TestStatusTailSamplerKeepsRecentDecisionsWhenFull(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	p := NewStatusTailSampler(rec, 0)
+
+	traceID := func(i int) trace.TraceID {
+		var id trace.TraceID
+		binary.BigEndian.PutUint64(id[8:], uint64(i)+1)
+		return id
+	}
+	span := func(id trace.TraceID, spanID byte, parent byte, attrs ...attribute.KeyValue) sdktrace.ReadOnlySpan {
+		stub := tracetest.SpanStub{
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{TraceID: id, SpanID: trace.SpanID{spanID}}),
+			Attributes:  attrs,
+		}
+		if parent != 0 {
+			stub.Parent = trace.NewSpanContext(trace.SpanContextConfig{TraceID: id, SpanID: trace.SpanID{parent}})
+		}
+		return stub.Snapshot()
+	}
+
+	// fill the cache with dropped traces, then decide to keep one trace, then push the cache over its
+	// limit so that an older decision has to be evicted
+	ok := semconv.HTTPStatusCodeKey.Int(200)
+	for i := 0; i < maxBufferedTraces-1; i++ {
+		p.OnEnd(span(traceID(i), 1, 0, ok))
+	}
+	kept := traceID(maxBufferedTraces)
+	p.OnEnd(span(kept, 1, 0))
+	p.OnEnd(span(traceID(maxBufferedTraces+1), 1, 0, ok))
+
+	before := len(rec.Ended())
+	p.OnEnd(span(kept, 2, 1))
+	if got := len(rec.Ended()) - before; got != 1 {
+		t.Errorf("span ending after its kept root: got %d spans passed on, want 1", got)
+	}
+}
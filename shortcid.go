@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+var shortCids int32
+
+// SetShortCids controls whether spans started by SpanWithCidAttribute, and attributes created by
+// CidAttributes, carry a shortened form of the cid alongside the full cid. The short form is intended
+// for display in backends with narrow columns, while the full cid remains available for queries. It is
+// disabled by default.
+func SetShortCids(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&shortCids, v)
+}
+
+// CidAttributes creates span attributes with standard names for representing a CID. When short cids
+// have been enabled using SetShortCids the attributes include ShortCidAttribute.
+func CidAttributes(c cid.Cid) []attribute.KeyValue {
+	if atomic.LoadInt32(&shortCids) == 0 {
+		return []attribute.KeyValue{CidAttribute(c)}
+	}
+	return []attribute.KeyValue{CidAttribute(c), ShortCidAttribute(c)}
+}
+
+// ShortCidAttribute creates a span attribute with a standard name for representing a CID in a short
+// form for display. The form keeps the first four characters, which identify the multibase, version
+// and codec, and the last eight characters, which are drawn from the end of the digest, so it is
+// deterministic and collisions between the cids in a trace are unlikely.
+func ShortCidAttribute(c cid.Cid) attribute.KeyValue {
+	return attribute.String("cid.short", shortCid(cidString(c)))
+}
+
+func shortCid(s string) string {
+	if len(s) <= 16 {
+		return s
+	}
+	return s[:4] + "…" + s[len(s)-8:]
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultShutdownTimeout is the time Shutdown allows for flushing and closing the tracing pipeline
+// when the context passed to it has no deadline
+const DefaultShutdownTimeout = 5 * time.Second
+
+var (
+	// pipelineMu serializes changes to the global tracing pipeline
+	pipelineMu sync.Mutex
+
+	shutdownMu    sync.Mutex
+	shutdownFuncs []func(context.Context) error
+)
+
+// OnShutdown registers a function to be called by Shutdown, for example to close an exporter or
+// recorder that is not owned by the global tracer provider. Functions are called in the reverse
+// order of their registration, after the global tracer provider has been shut down.
+func OnShutdown(f func(context.Context) error) {
+	shutdownMu.Lock()
+	shutdownFuncs = append(shutdownFuncs, f)
+	shutdownMu.Unlock()
+}
+
+// Shutdown flushes any spans buffered by the global tracer provider, shuts the provider down and
+// then calls the functions registered with OnShutdown. It is intended to be called once, at the
+// end of a node's shutdown sequence, so that the final spans of a session are exported. If the
+// context has no deadline then DefaultShutdownTimeout is applied. Every step is attempted even if an
+// earlier one fails and all errors are returned together.
+func Shutdown(ctx context.Context) error {
+	pipelineMu.Lock()
+	defer pipelineMu.Unlock()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultShutdownTimeout)
+		defer cancel()
+	}
+
+	var errs shutdownErrors
+	errs.add("shut down tracer provider", shutdownProvider(ctx, otel.GetTracerProvider()))
+
+	shutdownMu.Lock()
+	funcs := shutdownFuncs
+	shutdownFuncs = nil
+	shutdownMu.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		errs.add("shutdown hook", funcs[i](ctx))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Reinit replaces the global tracer provider and, if p is not nil, the global text map propagator,
+// and then flushes and shuts down the previous tracer provider. It supports rebuilding the tracing
+// pipeline in a running process, for example after a configuration reload or a repo switch. Spans
+// started after Reinit returns use the new provider. Spans that had already ended on the old provider
+// are exported as it drains, but spans still open on it when Reinit is called are dropped when they
+// end, since a provider that has been shut down no longer passes spans to its processors. If the
+// context has no deadline then DefaultShutdownTimeout is applied to the drain. Calls to Reinit and
+// Shutdown are serialized.
+func Reinit(ctx context.Context, tp trace.TracerProvider, p propagation.TextMapPropagator) error {
+	pipelineMu.Lock()
+	defer pipelineMu.Unlock()
+
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	if p != nil {
+		otel.SetTextMapPropagator(p)
+	}
+	if old == tp {
+		return nil
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultShutdownTimeout)
+		defer cancel()
+	}
+	return shutdownProvider(ctx, old)
+}
+
+// shutdownProvider flushes and shuts down a tracer provider if it supports those operations, as
+// the SDK's provider does
+func shutdownProvider(ctx context.Context, tp interface{}) error {
+	var errs shutdownErrors
+	if f, ok := tp.(interface{ ForceFlush(context.Context) error }); ok {
+		errs.add("flush", f.ForceFlush(ctx))
+	}
+	if s, ok := tp.(interface{ Shutdown(context.Context) error }); ok {
+		errs.add("shutdown", s.Shutdown(ctx))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// shutdownErrors collects the errors from each step of a shutdown
+type shutdownErrors []error
+
+func (e *shutdownErrors) add(step string, err error) {
+	if err != nil {
+		*e = append(*e, fmt.Errorf("%s: %w", step, err))
+	}
+}
+
+func (e shutdownErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
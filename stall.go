@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// StallWatcher records events on a fetch span when the fetch stops making progress and when it
+// recovers
+type StallWatcher struct {
+	span        trace.Span
+	after       time.Duration
+	outstanding func() int
+	wants       func() []cid.Cid
+	max         int
+	done        chan struct{}
+	once        sync.Once
+
+	mu           sync.Mutex
+	lastProgress time.Time
+	stalled      bool
+}
+
+// WatchStalls starts watching a fetch span. When no progress has been reported using Progress for
+// the given duration a "stalled" event is recorded holding the time since the last progress and the
+// number of outstanding wants returned by outstanding, which may be nil if the count is not known.
+// When progress resumes a "recovered" event is recorded. Stop must be called when the fetch completes.
+func WatchStalls(span trace.Span, after time.Duration, outstanding func() int) *StallWatcher {
+	w := &StallWatcher{
+		span:         span,
+		after:        after,
+		outstanding:  outstanding,
+		done:         make(chan struct{}),
+		lastProgress: now(),
+	}
+	if span.IsRecording() {
+		go w.run()
+	}
+	return w
+}
+
+// WatchStallsWithWantlist is like WatchStalls but takes the session's current want-list from wants.
+// The number of wants is recorded on the "stalled" event and a want-list snapshot holding up to max
+// cids is recorded alongside it, as by AddWantlistSnapshotEvent, so the wants that stopped making
+// progress are visible.
+func WatchStallsWithWantlist(span trace.Span, after time.Duration, max int, wants func() []cid.Cid) *StallWatcher {
+	w := &StallWatcher{
+		span:         span,
+		after:        after,
+		wants:        wants,
+		max:          max,
+		done:         make(chan struct{}),
+		lastProgress: now(),
+	}
+	if span.IsRecording() {
+		go w.run()
+	}
+	return w
+}
+
+// Progress reports that the fetch has received data
+func (w *StallWatcher) Progress() {
+	w.mu.Lock()
+	wasStalled := w.stalled
+	idle := since(w.lastProgress)
+	w.stalled = false
+	w.lastProgress = now()
+	w.mu.Unlock()
+
+	if wasStalled {
+		w.span.AddEvent("recovered", trace.WithAttributes(DurationAttribute("stalled", idle)))
+	}
+}
+
+// Stop stops watching the span
+func (w *StallWatcher) Stop() {
+	w.once.Do(func() { close(w.done) })
+}
+
+func (w *StallWatcher) run() {
+	wait := w.after
+	for {
+		tick, stop := after(wait)
+		select {
+		case <-w.done:
+			stop()
+			return
+		case <-tick:
+		}
+
+		w.mu.Lock()
+		idle := since(w.lastProgress)
+		if w.stalled || idle < w.after {
+			wait = w.after - idle
+			if w.stalled || wait <= 0 {
+				wait = w.after
+			}
+			w.mu.Unlock()
+			continue
+		}
+		w.stalled = true
+		w.mu.Unlock()
+
+		attrs := []attribute.KeyValue{DurationAttribute("elapsed", idle)}
+		var ws []cid.Cid
+		switch {
+		case w.wants != nil:
+			ws = w.wants()
+			attrs = append(attrs, attribute.Int("wants.outstanding.count", len(ws)))
+		case w.outstanding != nil:
+			attrs = append(attrs, attribute.Int("wants.outstanding.count", w.outstanding()))
+		}
+		w.span.AddEvent("stalled", trace.WithAttributes(attrs...))
+		if w.wants != nil {
+			AddWantlistSnapshotEvent(w.span, ws, w.max)
+		}
+		wait = w.after
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+func TestWatchStallsNilOutstanding(t *testing.T) {
+	withRecorder(t)
+	_, span := Span(context.Background(), "Test", "Fetch")
+	defer span.End()
+
+	w := WatchStalls(span, time.Millisecond, nil)
+	defer w.Stop()
+
+	ro := span.(sdktrace.ReadOnlySpan)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ro.Events()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no stalled event recorded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ev := ro.Events()[0]
+	if ev.Name != "stalled" {
+		t.Fatalf("got event %q, want stalled", ev.Name)
+	}
+	if _, ok := attributeMap(ev.Attributes)["wants.outstanding.count"]; ok {
+		t.Error("stalled event has an outstanding count although no count function was given")
+	}
+}
+
+func TestWatchStallsWithWantlistSnapshotsOnStall(t *testing.T) {
+	withRecorder(t)
+	_, span := Span(context.Background(), "Test", "Fetch")
+	defer span.End()
+
+	wants := []cid.Cid{
+		cid.NewCidV1(cid.Raw, mustMultihash(t, "a")),
+		cid.NewCidV1(cid.Raw, mustMultihash(t, "b")),
+	}
+	w := WatchStallsWithWantlist(span, time.Millisecond, 1, func() []cid.Cid { return wants })
+	defer w.Stop()
+
+	ro := span.(sdktrace.ReadOnlySpan)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ro.Events()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d events, want a stalled event and a snapshot", len(ro.Events()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	events := ro.Events()
+	if events[0].Name != "stalled" || events[1].Name != "wantlist snapshot" {
+		t.Fatalf("got events %q and %q, want stalled and wantlist snapshot", events[0].Name, events[1].Name)
+	}
+	if got := attributeMap(events[0].Attributes)["wants.outstanding.count"]; got != "2" {
+		t.Errorf("stalled outstanding count: got %q, want 2", got)
+	}
+	snapshot := attributeMap(events[1].Attributes)
+	if want := wants[0].String() + " and 1 more"; snapshot["wants"] != want || snapshot["wants.count"] != "2" {
+		t.Errorf("snapshot: got %v, want wants %q and count 2", snapshot, want)
+	}
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader is the name of the HTTP response header used to return the trace ID to clients
+const TraceIDHeader = "X-Trace-Id"
+
+// StreamingHandler wraps an http handler so that responses written by it are wrapped using
+// NewStreamingResponseWriter. It should be installed inside the handler that starts the request span.
+func StreamingHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(NewStreamingResponseWriter(r.Context(), w), r)
+	})
+}
+
+// NewStreamingResponseWriter wraps a response writer for a long running streamed response, such as a
+// chunked RPC API response. The trace ID of the span held in the context is set in the TraceIDHeader
+// header before the status or first chunk is written, and every flush of the response is recorded as
+// an event on the span with the chunk index and the number of bytes written since the previous flush.
+func NewStreamingResponseWriter(ctx context.Context, w http.ResponseWriter) http.ResponseWriter {
+	span := trace.SpanFromContext(ctx)
+	sw := &streamingResponseWriter{ResponseWriter: w, span: span}
+	if sc := span.SpanContext(); sc.IsValid() {
+		sw.traceID = sc.TraceID().String()
+	}
+	return sw
+}
+
+type streamingResponseWriter struct {
+	http.ResponseWriter
+	span        trace.Span
+	traceID     string
+	wroteHeader bool
+	chunks      int
+	unflushed   int64
+}
+
+var _ http.Flusher = (*streamingResponseWriter)(nil)
+
+func (w *streamingResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.traceID != "" {
+			w.Header().Set(TraceIDHeader, w.traceID)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *streamingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.unflushed += int64(n)
+	return n, err
+}
+
+func (w *streamingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+	if w.span.IsRecording() {
+		w.span.AddEvent("flush", trace.WithAttributes(
+			attribute.Int("chunk.index", w.chunks),
+			BytesAttribute("chunk.size", w.unflushed),
+		))
+	}
+	w.chunks++
+	w.unflushed = 0
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTimeoutSpan starts a new span and applies a timeout to the returned context. The configured
+// timeout is recorded as an attribute and, when the span is ended, it records whether this specific
+// timeout expired as opposed to the parent context being cancelled or reaching its own deadline.
+// The returned cancel function must be called to release the timeout's resources.
+func WithTimeoutSpan(ctx context.Context, componentName string, spanName string, d time.Duration) (context.Context, trace.Span, context.CancelFunc) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, d)
+	ctx, span := Span(ctx, componentName, spanName, trace.WithAttributes(DurationAttribute("timeout", d)))
+	if !span.IsRecording() {
+		return ctx, span, cancel
+	}
+
+	ts := &timeoutSpan{Span: span, parent: parent, ctx: ctx}
+	wrapRegistered(span, ts)
+	return trace.ContextWithSpan(ctx, ts), ts, cancel
+}
+
+type timeoutSpan struct {
+	trace.Span
+	parent context.Context
+	ctx    context.Context
+}
+
+func (s *timeoutSpan) unwrap() trace.Span { return s.Span }
+
+func (s *timeoutSpan) End(opts ...trace.SpanEndOption) {
+	expired := errors.Is(s.ctx.Err(), context.DeadlineExceeded) && s.parent.Err() == nil
+	s.SetAttributes(attribute.Bool("timeout.expired", expired))
+	s.Span.End(opts...)
+}
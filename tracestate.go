@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceStateKey is the key of the W3C tracestate list member that carries IPFS specific trace state
+const TraceStateKey = "ipfs"
+
+// TraceStateEntry holds the values carried in the ipfs tracestate list member. Its string form is a
+// semicolon separated list of fields, for example "o:gateway;s:keep". Unknown fields are ignored when
+// parsing so that new fields can be added without breaking older readers.
+type TraceStateEntry struct {
+	// Origin names the kind of ingress that started the trace, such as gateway, api or bitswap
+	Origin string
+
+	// SamplingHint is advice to downstream samplers, such as keep or debug
+	SamplingHint string
+}
+
+// String returns the tracestate value encoding of the entry
+func (e TraceStateEntry) String() string {
+	var fields []string
+	if e.Origin != "" {
+		fields = append(fields, "o:"+e.Origin)
+	}
+	if e.SamplingHint != "" {
+		fields = append(fields, "s:"+e.SamplingHint)
+	}
+	return strings.Join(fields, ";")
+}
+
+// ParseTraceStateEntry parses the value of an ipfs tracestate list member
+func ParseTraceStateEntry(v string) (TraceStateEntry, error) {
+	var e TraceStateEntry
+	for _, field := range strings.Split(v, ";") {
+		if field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return TraceStateEntry{}, fmt.Errorf("invalid ipfs tracestate field: %q", field)
+		}
+		switch parts[0] {
+		case "o":
+			e.Origin = validString(parts[1])
+		case "s":
+			e.SamplingHint = validString(parts[1])
+		}
+	}
+	return e, nil
+}
+
+// TraceStateEntryFromContext returns the ipfs tracestate entry carried by the span context held in
+// the context. The boolean result is false if there is no valid entry.
+func TraceStateEntryFromContext(ctx context.Context) (TraceStateEntry, bool) {
+	v := trace.SpanContextFromContext(ctx).TraceState().Get(TraceStateKey)
+	if v == "" {
+		return TraceStateEntry{}, false
+	}
+	e, err := ParseTraceStateEntry(v)
+	if err != nil {
+		return TraceStateEntry{}, false
+	}
+	return e, true
+}
+
+// WithTraceStateEntry returns a copy of the trace state with the ipfs list member set to the entry,
+// moved to the front of the list as required by the W3C specification for modified members. An empty
+// entry removes the member.
+func WithTraceStateEntry(ts trace.TraceState, e TraceStateEntry) (trace.TraceState, error) {
+	v := e.String()
+	if v == "" {
+		return ts.Delete(TraceStateKey), nil
+	}
+	return ts.Insert(TraceStateKey, v)
+}
@@ -3,37 +3,108 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
+	"unicode/utf8"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
-	blocks "github.com/ipfs/go-block-format"
 	cid "github.com/ipfs/go-cid"
-	path "github.com/ipfs/interface-go-ipfs-core/path"
 )
 
-// Span starts a new span using the standard IPFS tracing conventions.
+// Span starts a new span using the standard IPFS tracing conventions. Any attributes added to the
+// context using WithCommonAttributes or returned by registered enrichers are added to the span. No span is started if the component has
+// been disabled using SetComponentsEnabled.
 func Span(ctx context.Context, componentName string, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
-	return otel.Tracer("").Start(ctx, fmt.Sprintf("%s.%s", componentName, spanName), opts...)
+	if !ComponentEnabled(componentName) {
+		return ctx, disabledSpan
+	}
+
+	clock := customClock()
+	attrs := CommonAttributesFromContext(ctx)
+	enrichers := activeEnrichers()
+	if len(attrs) > 0 || clock != nil || len(enrichers) > 0 {
+		combined := getOptions()
+		defer putOptions(combined)
+		if clock != nil {
+			*combined = append(*combined, trace.WithTimestamp(clock.Now()))
+		}
+		if len(attrs) > 0 {
+			*combined = append(*combined, trace.WithAttributes(attrs...))
+		}
+		if len(enrichers) > 0 {
+			if ea := enrichStart(enrichers, ctx, componentName, spanName); len(ea) > 0 {
+				*combined = append(*combined, trace.WithAttributes(ea...))
+			}
+		}
+		*combined = append(*combined, opts...)
+		opts = *combined
+	}
+	name := fmt.Sprintf("%s.%s", componentName, spanName)
+	if strictUnitsEnabled() {
+		cfg := trace.NewSpanStartConfig(opts...)
+		checkUnits(name, cfg.Attributes())
+	}
+	ctx, span := otel.Tracer("").Start(ctx, name, opts...)
+	if clock != nil && span.IsRecording() {
+		ctx, span = withClock(ctx, span, clock)
+	}
+	if len(enrichers) > 0 && span.IsRecording() {
+		ctx, span = withEnrichers(ctx, span, enrichers, componentName, spanName)
+	}
+	if spanRegistryEnabled() && span.IsRecording() {
+		return registerSpan(ctx, span, componentName, spanName, opts)
+	}
+	return ctx, span
 }
 
 // SpanWithStringAttribute is a helper function to assist the common pattern of starting a new span
 // with a single string attribute
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithString option, which
+// records the same attributes.
 func SpanWithStringAttribute(ctx context.Context, componentName string, spanName string, k string, v string) (context.Context, trace.Span) {
-	return Span(ctx, componentName, spanName, trace.WithAttributes(attribute.String(k, v)))
+	attrs := getAttributes()
+	defer putAttributes(attrs)
+	*attrs = append(*attrs, attribute.String(k, v))
+	return Span(ctx, componentName, spanName, trace.WithAttributes(*attrs...))
 }
 
 // SpanWithIntAttribute is a helper function to assist the common pattern of starting a new span
 // with a single int attribute
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithInt option, which
+// records the same attributes.
 func SpanWithIntAttribute(ctx context.Context, componentName string, spanName string, k string, v int) (context.Context, trace.Span) {
-	return Span(ctx, componentName, spanName, trace.WithAttributes(attribute.Int(k, v)))
+	attrs := getAttributes()
+	defer putAttributes(attrs)
+	*attrs = append(*attrs, attribute.Int(k, v))
+	return Span(ctx, componentName, spanName, trace.WithAttributes(*attrs...))
+}
+
+// SpanWithAttributeSet is a helper function to assist the common pattern of starting a new span
+// with a set of attributes built once and reused across many spans, such as the child spans of a
+// single request. The saving is in building and deduplicating the set once: its attributes are still
+// copied into a new slice for every span, so there is no per-span allocation win over passing the
+// attributes directly.
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithAttributeSet option, which
+// records the same attributes.
+func SpanWithAttributeSet(ctx context.Context, componentName string, spanName string, set *attribute.Set) (context.Context, trace.Span) {
+	if set.Len() == 0 {
+		return Span(ctx, componentName, spanName)
+	}
+	return Span(ctx, componentName, spanName, trace.WithAttributes(set.ToSlice()...))
 }
 
 // SpanWithPathAttribute is a helper function to assist the common pattern of starting a new span
 // with a single path attribute
-func SpanWithPathAttribute(ctx context.Context, componentName string, spanName string, p path.Path) (context.Context, trace.Span) {
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithPath option, which
+// records the same attributes.
+func SpanWithPathAttribute(ctx context.Context, componentName string, spanName string, p Path) (context.Context, trace.Span) {
 	ctx, span := Span(ctx, componentName, spanName)
 	if span.IsRecording() {
 		span.SetAttributes(PathAttribute(p))
@@ -43,27 +114,36 @@ func SpanWithPathAttribute(ctx context.Context, componentName string, spanName s
 
 // SpanWithCidAttribute is a helper function to assist the common pattern of starting a new span
 // with a single cid attribute
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithCid option, which
+// records the same attributes.
 func SpanWithCidAttribute(ctx context.Context, componentName string, spanName string, c cid.Cid) (context.Context, trace.Span) {
 	ctx, span := Span(ctx, componentName, spanName)
 	if span.IsRecording() {
-		span.SetAttributes(attribute.String("cid", c.String()))
+		span.SetAttributes(CidAttributes(c)...)
 	}
 	return ctx, span
 }
 
 // SpanWithCidListAttribute is a helper function to assist the common pattern of starting a new span
-// with a list of cids as an attribute
+// with a list of cids as an attribute. Every cid is recorded in verbose traces.
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithCids option, which
+// records the same attributes.
 func SpanWithCidListAttribute(ctx context.Context, componentName string, spanName string, cs []cid.Cid) (context.Context, trace.Span) {
 	ctx, span := Span(ctx, componentName, spanName)
 	if span.IsRecording() {
-		span.SetAttributes(CidListAttribute(cs))
+		span.SetAttributes(VerboseCidListAttribute(ctx, cs), attribute.Int("cids.count", len(cs)))
 	}
 	return ctx, span
 }
 
 // SpanWithBlockAttribute is a helper function to assist the common pattern of starting a new span
 // with a single block attribute
-func SpanWithBlockAttribute(ctx context.Context, componentName string, spanName string, b blocks.Block) (context.Context, trace.Span) {
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithBlock option, which
+// records the same attributes.
+func SpanWithBlockAttribute(ctx context.Context, componentName string, spanName string, b Block) (context.Context, trace.Span) {
 	ctx, span := Span(ctx, componentName, spanName)
 	if span.IsRecording() {
 		span.SetAttributes(BlockAttribute(b))
@@ -72,76 +152,197 @@ func SpanWithBlockAttribute(ctx context.Context, componentName string, spanName
 }
 
 // SpanWithBlockListAttribute is a helper function to assist the common pattern of starting a new span
-// with a single attribute containing a list of blocks
-func SpanWithBlockListAttribute(ctx context.Context, componentName string, spanName string, bs []blocks.Block) (context.Context, trace.Span) {
+// with a single attribute containing a list of blocks. Every block is recorded in verbose traces. Use
+// SpanWithBlockListAttributeOf for a slice of a specific block type, such as []blocks.Block.
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithBlocks option, which
+// records the same attributes.
+func SpanWithBlockListAttribute(ctx context.Context, componentName string, spanName string, bs []Block) (context.Context, trace.Span) {
+	return SpanWithBlockListAttributeOf(ctx, componentName, spanName, bs)
+}
+
+// SpanWithBlockListAttributeOf is like SpanWithBlockListAttribute but accepts a slice of any block type
+//
+// Deprecated: Use Span from github.com/iand/go-ipfs-tracing/v2 with the WithBlocks option, which
+// records the same attributes.
+func SpanWithBlockListAttributeOf[B Block](ctx context.Context, componentName string, spanName string, bs []B) (context.Context, trace.Span) {
 	ctx, span := Span(ctx, componentName, spanName)
 	if span.IsRecording() {
-		span.SetAttributes(BlockListAttribute(bs))
+		span.SetAttributes(VerboseBlockListAttributeOf(ctx, bs), attribute.Int("blocks.count", len(bs)))
 	}
 	return ctx, span
 }
 
-// PathAttribute creates a span attribute with a standard name for representing a Path
-func PathAttribute(p path.Path) attribute.KeyValue {
-	return attribute.String("path", p.String())
+// Path is the interface of the paths used by the path helpers. It is satisfied by the paths of the
+// CoreAPI so that this package does not need to depend on it.
+type Path interface {
+	String() string
+}
+
+// Block is the interface of the blocks used by the block helpers. It is satisfied by blocks.Block so
+// that this package does not need to depend on go-block-format.
+type Block interface {
+	Cid() cid.Cid
 }
 
-// CidAttribute creates a span attribute with a standard name for representing a CID
+// PathAttribute creates a span attribute with a standard name for representing a Path. Invalid UTF-8
+// sequences in the path are replaced since they would cause collectors to reject the span. A nil path,
+// including a nil pointer of a path type, is represented as "nil".
+func PathAttribute(p Path) attribute.KeyValue {
+	if isNil(p) {
+		return attribute.String("path", "nil")
+	}
+	return attribute.String("path", validString(p.String()))
+}
+
+// CidAttribute creates a span attribute with a standard name for representing a CID. An undefined CID
+// is represented as "undefined".
 func CidAttribute(c cid.Cid) attribute.KeyValue {
-	return attribute.String("cid", c.String())
+	return attribute.String("cid", cidString(c))
 }
 
 // CidListAttribute creates a span attribute with a standard name for representing a list of CIDs
 func CidListAttribute(cs []cid.Cid) attribute.KeyValue {
-	var value string
+	return attribute.String("cids", cidListString(cs, 3))
+}
+
+// CidListAttributes creates span attributes with standard names for representing a list of CIDs and
+// the number of CIDs in the list. The count can be aggregated by trace backends, unlike the summary
+// held in the list attribute.
+func CidListAttributes(cs []cid.Cid) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		CidListAttribute(cs),
+		attribute.Int("cids.count", len(cs)),
+	}
+}
+
+// cidListString renders up to max cids from a list, summarizing the number remaining. A max outside
+// the range of the list is clamped to it.
+func cidListString(cs []cid.Cid, max int) string {
 	if len(cs) == 0 {
-		value = "empty list"
-	} else {
-		max := 3
-		if max > len(cs) {
-			max = len(cs)
-		}
+		return "empty list"
+	}
 
-		cids := make([]string, max)
-		for i := range cids {
-			cids[i] = cs[i].String()
-		}
+	if max > len(cs) {
+		max = len(cs)
+	}
+	if max < 0 {
+		max = 0
+	}
 
-		value = strings.Join(cids, ",")
+	cids := make([]string, max)
+	for i := range cids {
+		cids[i] = cidString(cs[i])
+	}
 
-		if max < len(cs) {
-			value += fmt.Sprintf(" and %d more", len(cs)-max)
-		}
+	if max == 0 {
+		return fmt.Sprintf("%d cids", len(cs))
+	}
+
+	value := strings.Join(cids, ",")
+
+	if max < len(cs) {
+		value += fmt.Sprintf(" and %d more", len(cs)-max)
+	}
+	return value
+}
+
+// cidString returns the string form of a cid, or "undefined" for cid.Undef, which would otherwise be
+// rendered as a bare multibase prefix
+func cidString(c cid.Cid) string {
+	if !c.Defined() {
+		return "undefined"
 	}
-	return attribute.String("cids", value)
+	return c.String()
+}
+
+// blockString returns the string form of a block's cid, or "nil" for a nil block
+func blockString(b Block) string {
+	if isNil(b) {
+		return "nil"
+	}
+	return cidString(b.Cid())
+}
+
+// isNil reports whether v is nil or holds a nil value of a type that can be nil, such as a nil
+// pointer to a block, which would otherwise panic when its methods are called
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// validString replaces any invalid UTF-8 sequences in s with the Unicode replacement character
+func validString(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+// BlockAttribute creates a span attribute with a standard name for representing a block. A nil block,
+// including a nil pointer of a block type, is represented as "nil".
+func BlockAttribute(b Block) attribute.KeyValue {
+	return attribute.String("block", blockString(b))
 }
 
-// BlockAttribute creates a span attribute with a standard name for representing a block
-func BlockAttribute(b blocks.Block) attribute.KeyValue {
-	return attribute.String("block", b.Cid().String())
+// BlockListAttribute creates a span attribute with a standard name for representing a list of blocks.
+// Use BlockListAttributeOf for a slice of a specific block type, such as []blocks.Block.
+func BlockListAttribute(bs []Block) attribute.KeyValue {
+	return BlockListAttributeOf(bs)
 }
 
-// BlockAttribute creates a span attribute with a standard name for representing a list of blocks
-func BlockListAttribute(bs []blocks.Block) attribute.KeyValue {
-	var value string
+// BlockListAttributeOf is like BlockListAttribute but accepts a slice of any block type
+func BlockListAttributeOf[B Block](bs []B) attribute.KeyValue {
+	return attribute.String("blocks", blockListString(bs, 3))
+}
+
+// blockListString renders up to max blocks from a list, summarizing the number remaining. A max
+// outside the range of the list is clamped to it.
+func blockListString[B Block](bs []B, max int) string {
 	if len(bs) == 0 {
-		value = "empty list"
-	} else {
-		max := 3
-		if max > len(bs) {
-			max = len(bs)
-		}
+		return "empty list"
+	}
 
-		cids := make([]string, max)
-		for i := range cids {
-			cids[i] = bs[i].Cid().String()
-		}
+	if max > len(bs) {
+		max = len(bs)
+	}
+	if max < 0 {
+		max = 0
+	}
 
-		value = strings.Join(cids, ",")
+	if max == 0 {
+		return fmt.Sprintf("%d blocks", len(bs))
+	}
 
-		if max < len(bs) {
-			value += fmt.Sprintf(" and %d more", len(bs)-max)
-		}
+	cids := make([]string, max)
+	for i := range cids {
+		cids[i] = blockString(bs[i])
+	}
+
+	value := strings.Join(cids, ",")
+
+	if max < len(bs) {
+		value += fmt.Sprintf(" and %d more", len(bs)-max)
+	}
+	return value
+}
+
+// BlockListAttributes creates span attributes with standard names for representing a list of blocks
+// and the number of blocks in the list. Use BlockListAttributesOf for a slice of a specific block type.
+func BlockListAttributes(bs []Block) []attribute.KeyValue {
+	return BlockListAttributesOf(bs)
+}
+
+// BlockListAttributesOf is like BlockListAttributes but accepts a slice of any block type
+func BlockListAttributesOf[B Block](bs []B) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		BlockListAttributeOf(bs),
+		attribute.Int("blocks.count", len(bs)),
 	}
-	return attribute.String("blocks", value)
 }
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+type testBlock struct {
+	c cid.Cid
+}
+
+func (b testBlock) Cid() cid.Cid { return b.c }
+
+// ptrBlock has a pointer receiver so, like blocks.BasicBlock, a nil *ptrBlock panics when Cid is called
+type ptrBlock struct {
+	c cid.Cid
+}
+
+func (b *ptrBlock) Cid() cid.Cid { return b.c }
+
+// ptrPath has a pointer receiver so a nil *ptrPath panics when String is called
+type ptrPath struct {
+	s string
+}
+
+func (p *ptrPath) String() string { return p.s }
+
+func TestAttributeHelpersGuardInvalidInput(t *testing.T) {
+	defined := cid.NewCidV1(cid.Raw, mustMultihash(t, "a"))
+
+	testCases := []struct {
+		name string
+		attr func() attribute.KeyValue
+		want attribute.KeyValue
+	}{
+		{
+			name: "nil path",
+			attr: func() attribute.KeyValue { return PathAttribute(nil) },
+			want: attribute.String("path", "nil"),
+		},
+		{
+			name: "invalid utf8 path",
+			attr: func() attribute.KeyValue { return PathAttribute(stringPath("/ipfs/\xff")) },
+			want: attribute.String("path", "/ipfs/�"),
+		},
+		{
+			name: "nil block",
+			attr: func() attribute.KeyValue { return BlockAttribute(nil) },
+			want: attribute.String("block", "nil"),
+		},
+		{
+			name: "typed nil path",
+			attr: func() attribute.KeyValue { return PathAttribute((*ptrPath)(nil)) },
+			want: attribute.String("path", "nil"),
+		},
+		{
+			name: "typed nil block",
+			attr: func() attribute.KeyValue { return BlockAttribute((*ptrBlock)(nil)) },
+			want: attribute.String("block", "nil"),
+		},
+		{
+			name: "block list of typed nil blocks",
+			attr: func() attribute.KeyValue { return BlockListAttributeOf([]*ptrBlock{nil, {c: defined}}) },
+			want: attribute.String("blocks", "nil,"+defined.String()),
+		},
+		{
+			name: "untyped nil block list",
+			attr: func() attribute.KeyValue { return BlockListAttribute(nil) },
+			want: attribute.String("blocks", "empty list"),
+		},
+		{
+			name: "block with undefined cid",
+			attr: func() attribute.KeyValue { return BlockAttribute(testBlock{}) },
+			want: attribute.String("block", "undefined"),
+		},
+		{
+			name: "block list with nil block",
+			attr: func() attribute.KeyValue { return BlockListAttribute([]Block{nil, testBlock{c: defined}}) },
+			want: attribute.String("blocks", "nil,"+defined.String()),
+		},
+		{
+			name: "block list of nil blocks",
+			attr: func() attribute.KeyValue { return BlockListAttributes([]Block{nil, nil, nil, nil})[0] },
+			want: attribute.String("blocks", "nil,nil,nil and 1 more"),
+		},
+		{
+			name: "empty block list",
+			attr: func() attribute.KeyValue { return BlockListAttribute([]Block(nil)) },
+			want: attribute.String("blocks", "empty list"),
+		},
+		{
+			name: "undefined cid",
+			attr: func() attribute.KeyValue { return CidAttribute(cid.Undef) },
+			want: attribute.String("cid", "undefined"),
+		},
+		{
+			name: "cid list with undefined cid",
+			attr: func() attribute.KeyValue { return CidListAttribute([]cid.Cid{cid.Undef, defined}) },
+			want: attribute.String("cids", "undefined,"+defined.String()),
+		},
+		{
+			name: "empty cid list",
+			attr: func() attribute.KeyValue { return CidListAttribute(nil) },
+			want: attribute.String("cids", "empty list"),
+		},
+		{
+			name: "undefined root cid",
+			attr: func() attribute.KeyValue { return RootCidAttribute(cid.Undef) },
+			want: attribute.String("root_cid", "undefined"),
+		},
+		{
+			name: "undefined short cid",
+			attr: func() attribute.KeyValue { return ShortCidAttribute(cid.Undef) },
+			want: attribute.String("cid.short", "undefined"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.attr(); got != tc.want {
+				t.Errorf("got %s=%q, want %s=%q", got.Key, got.Value.Emit(), tc.want.Key, tc.want.Value.Emit())
+			}
+		})
+	}
+}
+
+func TestSpanHelpersGuardInvalidInput(t *testing.T) {
+	rec := withRecorder(t)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name  string
+		start func() trace.Span
+		key   string
+		want  string
+	}{
+		{
+			name:  "nil path",
+			start: func() trace.Span { _, s := SpanWithPathAttribute(ctx, "Test", "Path", nil); return s },
+			key:   "path",
+			want:  "nil",
+		},
+		{
+			name:  "undefined cid",
+			start: func() trace.Span { _, s := SpanWithCidAttribute(ctx, "Test", "Cid", cid.Undef); return s },
+			key:   "cid",
+			want:  "undefined",
+		},
+		{
+			name: "cid list with undefined cid",
+			start: func() trace.Span {
+				_, s := SpanWithCidListAttribute(ctx, "Test", "Cids", []cid.Cid{cid.Undef})
+				return s
+			},
+			key:  "cids",
+			want: "undefined",
+		},
+		{
+			name:  "nil block",
+			start: func() trace.Span { _, s := SpanWithBlockAttribute(ctx, "Test", "Block", nil); return s },
+			key:   "block",
+			want:  "nil",
+		},
+		{
+			name: "block list with nil block",
+			start: func() trace.Span {
+				_, s := SpanWithBlockListAttribute(ctx, "Test", "Blocks", []Block{nil})
+				return s
+			},
+			key:  "blocks",
+			want: "nil",
+		},
+		{
+			name: "block list with typed nil block",
+			start: func() trace.Span {
+				_, s := SpanWithBlockListAttributeOf(ctx, "Test", "Blocks", []*ptrBlock{nil})
+				return s
+			},
+			key:  "blocks",
+			want: "nil",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.start().End()
+			ended := rec.Ended()
+			if got := attributeMap(ended[len(ended)-1].Attributes())[tc.key]; got != tc.want {
+				t.Errorf("%s: got %q, want %q", tc.key, got, tc.want)
+			}
+		})
+	}
+}
+
+// The non-generic list helpers can be used as function values, as they could before the generic
+// variants were added
+var (
+	_ func([]Block) attribute.KeyValue                                             = BlockListAttribute
+	_ func([]Block) []attribute.KeyValue                                           = BlockListAttributes
+	_ func(context.Context, string, string, []Block) (context.Context, trace.Span) = SpanWithBlockListAttribute
+)
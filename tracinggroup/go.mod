@@ -0,0 +1,31 @@
+module github.com/iand/go-ipfs-tracing/tracinggroup
+
+go 1.18
+
+require (
+	go.opentelemetry.io/otel v1.6.1
+	go.opentelemetry.io/otel/sdk v1.6.1
+	go.opentelemetry.io/otel/trace v1.6.1
+	golang.org/x/sync v0.1.0
+)
+
+require (
+	github.com/ipfs/go-cid v0.1.0 // indirect
+	github.com/multiformats/go-multihash v0.0.15 // indirect
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/iand/go-ipfs-tracing v0.0.0-20261014061556-82ae76b35022
+	github.com/klauspost/cpuid/v2 v2.0.6 // indirect
+	github.com/minio/blake2b-simd v0.0.0-20160723061019-3f5f724cb5b1 // indirect
+	github.com/minio/sha256-simd v1.0.0 // indirect
+	github.com/mr-tron/base58 v1.2.0 // indirect
+	github.com/multiformats/go-base32 v0.0.3 // indirect
+	github.com/multiformats/go-base36 v0.1.0 // indirect
+	github.com/multiformats/go-multibase v0.0.3 // indirect
+	github.com/multiformats/go-varint v0.0.6 // indirect
+	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a // indirect
+	golang.org/x/sys v0.0.0-20210514084401-e8d321eab015 // indirect
+)
@@ -0,0 +1,127 @@
+// Package tracinggroup provides trace aware wrappers for concurrency primitives used by IPFS nodes.
+package tracinggroup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
+	tracing "github.com/iand/go-ipfs-tracing"
+)
+
+// Singleflight wraps a singleflight.Group so that callers whose requests are coalesced are traced
+// correctly. Each call to Do starts a span. The first caller for a key becomes the leader and the
+// shared work runs within its span, using a context that keeps the leader's values but is detached
+// from its cancellation so that a leader giving up does not fail the other callers. The spans of the
+// other callers are linked to the leader's span and marked as coalesced, and the leader's span records
+// how many callers joined it. The zero value is ready to use but starts spans with an empty component
+// and span name.
+type Singleflight[T any] struct {
+	componentName string
+	spanName      string
+	group         singleflight.Group
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is the leader of an in-flight call for a key
+type call struct {
+	ctx       context.Context
+	followers int
+}
+
+// NewSingleflight creates a Singleflight whose spans are started for the named component with the
+// given span name
+func NewSingleflight[T any](componentName string, spanName string) *Singleflight[T] {
+	return &Singleflight[T]{
+		componentName: componentName,
+		spanName:      spanName,
+		calls:         make(map[string]*call),
+	}
+}
+
+// Do executes fn for the key, making sure only one execution is in flight for a given key at a time.
+// Callers that arrive while an execution is in flight wait for it and receive the same result. The
+// shared result reports whether the result was given to more than one caller. If the context of a
+// caller is done before the result is ready then Do returns the context's error to that caller while
+// the execution continues for the others.
+func (s *Singleflight[T]) Do(ctx context.Context, key string, fn func(context.Context) (T, error)) (v T, shared bool, err error) {
+	// The lock is held until the call has been passed to the group so that a caller is only treated as
+	// a follower while the leader's execution is still registered with the group, and as the leader
+	// only when the group will run its function.
+	s.mu.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]*call)
+	}
+	c, ok := s.calls[key]
+	var span trace.Span
+	if ok {
+		c.followers++
+		ctx, span = tracing.SpanForCoalesced(ctx, s.componentName, s.spanName, trace.SpanContextFromContext(c.ctx))
+	} else {
+		ctx, span = tracing.Span(ctx, s.componentName, s.spanName)
+		c = &call{ctx: detach(ctx)}
+		s.calls[key] = c
+	}
+	ch := s.group.DoChan(key, func() (interface{}, error) {
+		defer s.forget(key, c)
+		return fn(c.ctx)
+	})
+	s.mu.Unlock()
+
+	select {
+	case res := <-ch:
+		if !ok {
+			s.mu.Lock()
+			tracing.SetCoalescedFollowers(span, c.followers)
+			s.mu.Unlock()
+		}
+		if res.Val != nil {
+			v = res.Val.(T)
+		}
+		shared, err = res.Shared, res.Err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	tracing.EndSpan(span, s.componentName, err)
+	return v, shared, err
+}
+
+// Forget tells the group to forget about a key. Future calls to Do for the key will execute the
+// function rather than waiting for an earlier call to complete.
+func (s *Singleflight[T]) Forget(key string) {
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.group.Forget(key)
+	s.mu.Unlock()
+}
+
+// forget removes the leader of a call once its execution has finished, unless the key has since been
+// taken by another call. The key is removed from the group at the same time so that a caller arriving
+// after the leader has been removed starts a new execution instead of joining the finishing one.
+func (s *Singleflight[T]) forget(key string, c *call) {
+	s.mu.Lock()
+	if s.calls[key] == c {
+		delete(s.calls, key)
+		s.group.Forget(key)
+	}
+	s.mu.Unlock()
+}
+
+// detach returns a context holding the values of ctx that is never cancelled and has no deadline
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
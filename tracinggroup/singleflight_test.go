@@ -0,0 +1,146 @@
+package tracinggroup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func withRecorder(t testing.TB) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(rec),
+	))
+	return rec
+}
+
+func coalesced(s sdktrace.ReadOnlySpan) bool {
+	for _, kv := range s.Attributes() {
+		if kv.Key == "coalesced" {
+			return kv.Value.AsBool()
+		}
+	}
+	return false
+}
+
+func TestSingleflightZeroValue(t *testing.T) {
+	var s Singleflight[int]
+	v, _, err := s.Do(context.Background(), "key", func(context.Context) (int, error) { return 1, nil })
+	if err != nil || v != 1 {
+		t.Fatalf("got %d, %v, want 1, nil", v, err)
+	}
+	s.Forget("key")
+}
+
+func TestSingleflightLinksFollowerToLeader(t *testing.T) {
+	rec := withRecorder(t)
+	s := NewSingleflight[int]("Test", "Fetch")
+
+	release := make(chan struct{})
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		_, _, _ = s.Do(context.Background(), "key", func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+	}()
+
+	// wait for the leader to be registered before joining it
+	for {
+		s.mu.Lock()
+		_, ok := s.calls["key"]
+		s.mu.Unlock()
+		if ok {
+			break
+		}
+	}
+
+	followerDone := make(chan struct{})
+	go func() {
+		defer close(followerDone)
+		v, shared, err := s.Do(context.Background(), "key", func(context.Context) (int, error) {
+			t.Error("follower function ran")
+			return 2, nil
+		})
+		if v != 1 || !shared || err != nil {
+			t.Errorf("follower got %d, %v, %v, want 1, true, nil", v, shared, err)
+		}
+	}()
+
+	for {
+		s.mu.Lock()
+		n := s.calls["key"].followers
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+	}
+	close(release)
+	<-leaderDone
+	<-followerDone
+
+	var leader, follower sdktrace.ReadOnlySpan
+	for _, span := range rec.Ended() {
+		if coalesced(span) {
+			follower = span
+		} else {
+			leader = span
+		}
+	}
+	if leader == nil || follower == nil {
+		t.Fatal("want one leader span and one coalesced span")
+	}
+	if links := follower.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != leader.SpanContext().SpanID() {
+		t.Errorf("follower links: got %v, want a link to the leader", links)
+	}
+	want := attribute.Int("coalesced.followers.count", 1)
+	found := false
+	for _, kv := range leader.Attributes() {
+		if kv == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("leader attributes: got %v, want %s=1", leader.Attributes(), want.Key)
+	}
+}
+
+// TestSingleflightLeadersRunFunction checks that, however calls overlap, the callers whose spans are
+// not marked as coalesced are exactly those whose function ran
+func TestSingleflightLeadersRunFunction(t *testing.T) {
+	rec := withRecorder(t)
+	s := NewSingleflight[int]("Test", "Fetch")
+
+	var runs int64
+	var wg sync.WaitGroup
+	for i := 0; i < 5000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = s.Do(context.Background(), "key", func(context.Context) (int, error) {
+				atomic.AddInt64(&runs, 1)
+				return 1, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	var leaders int64
+	for _, span := range rec.Ended() {
+		if !coalesced(span) {
+			leaders++
+		}
+	}
+	if leaders != atomic.LoadInt64(&runs) {
+		t.Errorf("got %d leader spans, want one for each of the %d executions", leaders, runs)
+	}
+}
@@ -0,0 +1,115 @@
+// Package tracingtest provides utilities for testing the propagation of trace context between
+// IPFS nodes running in the same process.
+package tracingtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Node is an in-process stand-in for an IPFS node with its own tracer provider and span recorder
+type Node struct {
+	Name       string
+	Provider   *sdktrace.TracerProvider
+	Recorder   *tracetest.SpanRecorder
+	Propagator propagation.TextMapPropagator
+}
+
+// NewNode creates a node that samples every span and records them in memory. The node's resource
+// carries its name as the service name so spans can be attributed to the node that recorded them.
+func NewNode(name string) *Node {
+	rec := tracetest.NewSpanRecorder()
+	return &Node{
+		Name: name,
+		Provider: sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithSpanProcessor(rec),
+			sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+		),
+		Recorder:   rec,
+		Propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+// Start starts a new span using the node's tracer provider
+func (n *Node) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return n.Provider.Tracer("").Start(ctx, spanName, opts...)
+}
+
+// Spans returns the spans that have been ended on the node
+func (n *Node) Spans() []sdktrace.ReadOnlySpan {
+	return n.Recorder.Ended()
+}
+
+// Carrier transfers trace context from one node to another, returning the context the receiving node
+// should start its spans from. Implementations typically encode the context into a message on the
+// sending node and decode it on the receiving node.
+type Carrier func(ctx context.Context, from, to *Node) context.Context
+
+// TextMapCarrier is a Carrier that transfers trace context using the nodes' text map propagators,
+// in the same way as HTTP headers or message metadata
+func TextMapCarrier(ctx context.Context, from, to *Node) context.Context {
+	carrier := propagation.MapCarrier{}
+	from.Propagator.Inject(ctx, carrier)
+	return to.Propagator.Extract(context.Background(), carrier)
+}
+
+// Stitch starts a span on the sending node, transfers its context to the receiving node using the
+// carrier and starts a child span on the receiving node. It then asserts that the spans recorded by
+// both nodes form a single trace.
+func Stitch(t testing.TB, from, to *Node, carry Carrier) {
+	t.Helper()
+
+	ctx, sendSpan := from.Start(context.Background(), "Send", trace.WithSpanKind(trace.SpanKindClient))
+	rctx := carry(ctx, from, to)
+	_, recvSpan := to.Start(rctx, "Receive", trace.WithSpanKind(trace.SpanKindServer))
+	recvSpan.End()
+	sendSpan.End()
+
+	AssertSingleTrace(t, from, to)
+}
+
+// AssertSingleTrace asserts that the spans ended on all the nodes belong to the same trace, that
+// there is exactly one root span and that the parent of every other span was recorded by one of
+// the nodes
+func AssertSingleTrace(t testing.TB, nodes ...*Node) {
+	t.Helper()
+
+	var spans []sdktrace.ReadOnlySpan
+	for _, n := range nodes {
+		spans = append(spans, n.Spans()...)
+	}
+	if len(spans) == 0 {
+		t.Fatalf("no spans were recorded")
+	}
+
+	traceID := spans[0].SpanContext().TraceID()
+	ids := make(map[trace.SpanID]bool, len(spans))
+	for _, s := range spans {
+		if got := s.SpanContext().TraceID(); got != traceID {
+			t.Errorf("span %q has trace id %s, wanted %s", s.Name(), got, traceID)
+		}
+		ids[s.SpanContext().SpanID()] = true
+	}
+
+	roots := 0
+	for _, s := range spans {
+		if !s.Parent().IsValid() {
+			roots++
+			continue
+		}
+		if !ids[s.Parent().SpanID()] {
+			t.Errorf("parent %s of span %q was not recorded by any node", s.Parent().SpanID(), s.Name())
+		}
+	}
+	if roots != 1 {
+		t.Errorf("got %d root spans, wanted 1", roots)
+	}
+}
@@ -0,0 +1,69 @@
+package tracingtest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// recordingTB captures the failures reported to it instead of failing the test. Like testing.T it
+// stops the calling goroutine on Fatalf, so checks using it must be run by run.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// run calls f in its own goroutine and waits for it to finish or stop
+func (r *recordingTB) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+func TestStitchTextMapCarrier(t *testing.T) {
+	from, to := NewNode("a"), NewNode("b")
+	Stitch(t, from, to, TextMapCarrier)
+
+	if len(from.Spans()) != 1 || len(to.Spans()) != 1 {
+		t.Fatalf("got %d and %d spans, want one on each node", len(from.Spans()), len(to.Spans()))
+	}
+	if to.Spans()[0].Parent().SpanID() != from.Spans()[0].SpanContext().SpanID() {
+		t.Error("receiving span is not a child of the sending span")
+	}
+	if !to.Spans()[0].Parent().IsRemote() {
+		t.Error("receiving span's parent is not marked remote")
+	}
+}
+
+func TestAssertSingleTraceDetectsBrokenPropagation(t *testing.T) {
+	dropContext := func(ctx context.Context, from, to *Node) context.Context { return context.Background() }
+
+	tb := &recordingTB{TB: t}
+	tb.run(func() { Stitch(tb, NewNode("a"), NewNode("b"), dropContext) })
+	if len(tb.failures) == 0 {
+		t.Error("a carrier that drops the trace context was not reported")
+	}
+}
+
+func TestAssertSingleTraceNoSpans(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	tb.run(func() { AssertSingleTrace(tb, NewNode("a")) })
+	if len(tb.failures) != 1 {
+		t.Errorf("got failures %q, want one for the missing spans", tb.failures)
+	}
+}
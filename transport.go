@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TransportAttribute creates a span attribute with a standard name for representing the transport used
+// for a connection, such as tcp, quic, webtransport or websocket
+func TransportAttribute(transport string) attribute.KeyValue {
+	return attribute.String("transport", transport)
+}
+
+// SecurityProtocolAttribute creates a span attribute with a standard name for representing the security
+// protocol negotiated for a connection, such as tls or noise
+func SecurityProtocolAttribute(protocol string) attribute.KeyValue {
+	return attribute.String("transport.security", protocol)
+}
+
+// CertHashesAttribute creates a span attribute with a standard name for representing the number of
+// certificate hashes present in a WebTransport multiaddr
+func CertHashesAttribute(n int) attribute.KeyValue {
+	return attribute.Int("webtransport.certhashes.count", n)
+}
+
+// TLSVersionAttribute creates a span attribute with a standard name for representing a TLS protocol
+// version
+func TLSVersionAttribute(version uint16) attribute.KeyValue {
+	return attribute.String("tls.version", tlsVersionName(version))
+}
+
+// TLSAttributes creates span attributes with standard names describing a TLS connection
+func TLSAttributes(cs tls.ConnectionState) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		TLSVersionAttribute(cs.Version),
+		attribute.String("tls.cipher", tls.CipherSuiteName(cs.CipherSuite)),
+		attribute.String("tls.alpn", cs.NegotiatedProtocol),
+		attribute.Bool("tls.resumed", cs.DidResume),
+	}
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
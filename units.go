@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// unitSuffixes are the key suffixes that identify the unit of a numeric attribute
+var unitSuffixes = []string{"_bytes", "_ms", "_us", "_ns", "_s"}
+
+// countSuffixes are the key suffixes that identify a numeric attribute as a dimensionless count or index
+var countSuffixes = []string{".count", "_count", ".index", ".depth", ".capacity"}
+
+var strictUnits int32
+
+// SetStrictUnits controls whether spans started by this package are checked for numeric start
+// attributes that do not declare a unit. Keys of numeric attributes are expected to end with a unit
+// suffix such as _bytes or _ms, or a suffix such as .count identifying a dimensionless value. When
+// strict mode is enabled any violation is reported to the OpenTelemetry error handler. Strict mode is
+// intended for development and is disabled by default.
+func SetStrictUnits(strict bool) {
+	var v int32
+	if strict {
+		v = 1
+	}
+	atomic.StoreInt32(&strictUnits, v)
+}
+
+// BytesAttribute creates a span attribute for a size in bytes, adding the _bytes unit suffix to the key
+// if it is not already present
+func BytesAttribute(k string, n int64) attribute.KeyValue {
+	if !strings.HasSuffix(k, "_bytes") {
+		k += "_bytes"
+	}
+	return attribute.Int64(k, n)
+}
+
+// DurationAttribute creates a span attribute for a duration in milliseconds, adding the _ms unit
+// suffix to the key if it is not already present
+func DurationAttribute(k string, d time.Duration) attribute.KeyValue {
+	if !strings.HasSuffix(k, "_ms") {
+		k += "_ms"
+	}
+	return attribute.Int64(k, d.Milliseconds())
+}
+
+// BlockSizeAttribute creates a span attribute with a standard name for representing the size of a
+// block in bytes
+func BlockSizeAttribute(n int) attribute.KeyValue {
+	return attribute.Int64("ipfs.block.size_bytes", int64(n))
+}
+
+// checkUnits reports numeric attributes without a unit or count suffix when strict mode is enabled
+func checkUnits(spanName string, attrs []attribute.KeyValue) {
+	for _, kv := range attrs {
+		switch kv.Value.Type() {
+		case attribute.INT64, attribute.FLOAT64, attribute.INT64SLICE, attribute.FLOAT64SLICE:
+		default:
+			continue
+		}
+		if !hasSuffix(string(kv.Key), unitSuffixes) && !hasSuffix(string(kv.Key), countSuffixes) {
+			otel.Handle(fmt.Errorf("tracing: numeric attribute %q on span %q has no unit suffix", kv.Key, spanName))
+		}
+	}
+}
+
+func strictUnitsEnabled() bool {
+	return atomic.LoadInt32(&strictUnits) == 1
+}
+
+func hasSuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
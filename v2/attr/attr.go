@@ -0,0 +1,49 @@
+// Package attr provides typed builders for the span attributes used by IPFS components. Each builder
+// produces the same key and value as the corresponding helper of version one of the tracing API.
+package attr
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	v1 "github.com/iand/go-ipfs-tracing"
+	cid "github.com/ipfs/go-cid"
+	multihash "github.com/multiformats/go-multihash"
+)
+
+// Path creates the standard attribute for a path
+func Path(p v1.Path) attribute.KeyValue { return v1.PathAttribute(p) }
+
+// Cid creates the standard attribute for a cid
+func Cid(c cid.Cid) attribute.KeyValue { return v1.CidAttribute(c) }
+
+// ShortCid creates the standard attribute for the short display form of a cid
+func ShortCid(c cid.Cid) attribute.KeyValue { return v1.ShortCidAttribute(c) }
+
+// Cids creates the standard attributes for a list of cids and its length
+func Cids(cs []cid.Cid) []attribute.KeyValue { return v1.CidListAttributes(cs) }
+
+// RootCid creates the standard attribute for the root cid of a request
+func RootCid(c cid.Cid) attribute.KeyValue { return v1.RootCidAttribute(c) }
+
+// Block creates the standard attribute for a block
+func Block(b v1.Block) attribute.KeyValue { return v1.BlockAttribute(b) }
+
+// Blocks creates the standard attributes for a list of blocks and its length
+func Blocks[B v1.Block](bs []B) []attribute.KeyValue { return v1.BlockListAttributesOf(bs) }
+
+// BlockSize creates the standard attribute for the size of a block in bytes
+func BlockSize(n int) attribute.KeyValue { return v1.BlockSizeAttribute(n) }
+
+// Multihash creates the standard attributes for a multihash and its decomposition
+func Multihash(mh multihash.Multihash) []attribute.KeyValue { return v1.MultihashAttributes(mh) }
+
+// Bytes creates an attribute for a size in bytes, adding the _bytes unit suffix to the key
+func Bytes(k string, n int64) attribute.KeyValue { return v1.BytesAttribute(k, n) }
+
+// Duration creates an attribute for a duration in milliseconds, adding the _ms unit suffix to the key
+func Duration(k string, d time.Duration) attribute.KeyValue { return v1.DurationAttribute(k, d) }
+
+// RequestID creates the standard attribute for a request id
+func RequestID(id string) attribute.KeyValue { return v1.RequestIDAttribute(id) }
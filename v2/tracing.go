@@ -0,0 +1,151 @@
+// Package tracing provides the second version of the API for instrumenting IPFS components with
+// OpenTelemetry. Spans are started by component tracers and configured using options instead of a
+// helper function for each combination of attributes. Spans and attributes are identical to those
+// produced by version one, which remains the implementation, so the two may be used together while
+// code migrates.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	v1 "github.com/iand/go-ipfs-tracing"
+	cid "github.com/ipfs/go-cid"
+)
+
+// Tracer starts spans for a single component
+type Tracer struct {
+	component string
+}
+
+// Component returns the tracer for the named component
+func Component(name string) Tracer {
+	return Tracer{component: name}
+}
+
+// Name returns the name of the tracer's component
+func (t Tracer) Name() string {
+	return t.component
+}
+
+// Start starts a new span for the tracer's component, as described by Span
+func (t Tracer) Start(ctx context.Context, spanName string, opts ...Option) (context.Context, trace.Span) {
+	return Span(ctx, t.component, spanName, opts...)
+}
+
+// Span starts a new span named after the component and span name using the standard IPFS tracing
+// conventions, configured by the given options. Attributes given using WithAttributes and the options
+// built on it are start attributes, visible to samplers, while the IPFS value options add theirs once
+// the span has started, matching the version one helpers.
+func Span(ctx context.Context, componentName string, spanName string, opts ...Option) (context.Context, trace.Span) {
+	var cfg spanConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, span := v1.Span(ctx, componentName, spanName, cfg.startOptions...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	var attrs []attribute.KeyValue
+	for _, f := range cfg.attributes {
+		attrs = append(attrs, f(ctx)...)
+	}
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+// Option configures a span started by Span
+type Option func(*spanConfig)
+
+type spanConfig struct {
+	startOptions []trace.SpanStartOption
+
+	// attributes are evaluated once the span has started since some depend on the span's context,
+	// such as whether the trace is verbose
+	attributes []func(context.Context) []attribute.KeyValue
+}
+
+func withAttributes(f func(context.Context) []attribute.KeyValue) Option {
+	return func(cfg *spanConfig) {
+		cfg.attributes = append(cfg.attributes, f)
+	}
+}
+
+// WithStartOptions passes OpenTelemetry span start options, such as links or a span kind, to the
+// tracer when the span is started
+func WithStartOptions(opts ...trace.SpanStartOption) Option {
+	return func(cfg *spanConfig) {
+		cfg.startOptions = append(cfg.startOptions, opts...)
+	}
+}
+
+// WithKind sets the kind of the span
+func WithKind(kind trace.SpanKind) Option {
+	return WithStartOptions(trace.WithSpanKind(kind))
+}
+
+// WithAttributes adds start attributes to the span
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return WithStartOptions(trace.WithAttributes(attrs...))
+}
+
+// WithAttributeSet adds a set of start attributes built once and reused across many spans. The
+// attributes are copied for every span, as by the version one helper.
+func WithAttributeSet(set *attribute.Set) Option {
+	if set.Len() == 0 {
+		return func(*spanConfig) {}
+	}
+	return WithAttributes(set.ToSlice()...)
+}
+
+// WithString adds a string attribute to the span
+func WithString(k string, v string) Option {
+	return WithAttributes(attribute.String(k, v))
+}
+
+// WithInt adds an int attribute to the span
+func WithInt(k string, v int) Option {
+	return WithAttributes(attribute.Int(k, v))
+}
+
+// WithPath adds the standard path attribute to the span
+func WithPath(p v1.Path) Option {
+	return withAttributes(func(context.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{v1.PathAttribute(p)}
+	})
+}
+
+// WithCid adds the standard cid attributes to the span, including the short form if enabled
+func WithCid(c cid.Cid) Option {
+	return withAttributes(func(context.Context) []attribute.KeyValue {
+		return v1.CidAttributes(c)
+	})
+}
+
+// WithCids adds the standard cid list and count attributes to the span. Every cid is recorded in
+// verbose traces.
+func WithCids(cs []cid.Cid) Option {
+	return withAttributes(func(ctx context.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{v1.VerboseCidListAttribute(ctx, cs), attribute.Int("cids.count", len(cs))}
+	})
+}
+
+// WithBlock adds the standard block attribute to the span
+func WithBlock(b v1.Block) Option {
+	return withAttributes(func(context.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{v1.BlockAttribute(b)}
+	})
+}
+
+// WithBlocks adds the standard block list and count attributes to the span. Every block is recorded
+// in verbose traces.
+func WithBlocks[B v1.Block](bs []B) Option {
+	return withAttributes(func(ctx context.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{v1.VerboseBlockListAttributeOf(ctx, bs), attribute.Int("blocks.count", len(bs))}
+	})
+}
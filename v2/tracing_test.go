@@ -0,0 +1,285 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	v1 "github.com/iand/go-ipfs-tracing"
+	cid "github.com/ipfs/go-cid"
+	multihash "github.com/multiformats/go-multihash"
+)
+
+// startCapture records the attributes each span holds when it starts, which are the attributes visible
+// to samplers
+type startCapture struct {
+	mu    sync.Mutex
+	attrs map[trace.SpanID]map[string]string
+}
+
+func (c *startCapture) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	c.mu.Lock()
+	c.attrs[s.SpanContext().SpanID()] = attributeMap(s.Attributes())
+	c.mu.Unlock()
+}
+
+func (c *startCapture) OnEnd(s sdktrace.ReadOnlySpan)        {}
+func (c *startCapture) Shutdown(ctx context.Context) error   { return nil }
+func (c *startCapture) ForceFlush(ctx context.Context) error { return nil }
+
+func attributeMap(attrs []attribute.KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+type testPath string
+
+func (p testPath) String() string { return string(p) }
+
+type testBlock struct {
+	c cid.Cid
+}
+
+func (b testBlock) Cid() cid.Cid { return b.c }
+
+// recordedSpan is what a migration must preserve about a span
+type recordedSpan struct {
+	name       string
+	startAttrs map[string]string
+	attrs      map[string]string
+}
+
+func record(t *testing.T, ctx context.Context, start func(context.Context) trace.Span) recordedSpan {
+	t.Helper()
+	capture := &startCapture{attrs: map[trace.SpanID]map[string]string{}}
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(capture),
+		sdktrace.WithSpanProcessor(rec),
+	))
+
+	start(ctx).End()
+	ended := rec.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+	s := ended[0]
+	return recordedSpan{
+		name:       s.Name(),
+		startAttrs: capture.attrs[s.SpanContext().SpanID()],
+		attrs:      attributeMap(s.Attributes()),
+	}
+}
+
+func testCids(t *testing.T, n int) []cid.Cid {
+	t.Helper()
+	cs := make([]cid.Cid, n)
+	for i := range cs {
+		mh, err := multihash.Sum([]byte(fmt.Sprint(i)), multihash.SHA2_256, -1)
+		if err != nil {
+			t.Fatalf("hashing: %v", err)
+		}
+		cs[i] = cid.NewCidV1(cid.Raw, mh)
+	}
+	return cs
+}
+
+func TestSpanMatchesVersionOneHelpers(t *testing.T) {
+	cs := testCids(t, 5)
+	blocks := []testBlock{{c: cs[0]}, {c: cs[1]}, {c: cs[2]}, {c: cs[3]}}
+	set := attribute.NewSet(attribute.String("request_id", "r1"), attribute.Int("attempt", 2))
+
+	testCases := []struct {
+		name string
+		v1   func(context.Context) trace.Span
+		v2   func(context.Context) trace.Span
+	}{
+		{
+			name: "string",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithStringAttribute(ctx, "Test", "Op", "key", "value")
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span {
+				_, s := Span(ctx, "Test", "Op", WithString("key", "value"))
+				return s
+			},
+		},
+		{
+			name: "int",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithIntAttribute(ctx, "Test", "Op", "key", 7)
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span { _, s := Span(ctx, "Test", "Op", WithInt("key", 7)); return s },
+		},
+		{
+			name: "attribute set",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithAttributeSet(ctx, "Test", "Op", &set)
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span {
+				_, s := Span(ctx, "Test", "Op", WithAttributeSet(&set))
+				return s
+			},
+		},
+		{
+			name: "empty attribute set",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithAttributeSet(ctx, "Test", "Op", attribute.EmptySet())
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span {
+				_, s := Span(ctx, "Test", "Op", WithAttributeSet(attribute.EmptySet()))
+				return s
+			},
+		},
+		{
+			name: "path",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithPathAttribute(ctx, "Test", "Op", testPath("/ipfs/"+cs[0].String()))
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span {
+				_, s := Span(ctx, "Test", "Op", WithPath(testPath("/ipfs/"+cs[0].String())))
+				return s
+			},
+		},
+		{
+			name: "cid",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithCidAttribute(ctx, "Test", "Op", cs[0])
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span { _, s := Span(ctx, "Test", "Op", WithCid(cs[0])); return s },
+		},
+		{
+			name: "cid list",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithCidListAttribute(ctx, "Test", "Op", cs)
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span { _, s := Span(ctx, "Test", "Op", WithCids(cs)); return s },
+		},
+		{
+			name: "block",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithBlockAttribute(ctx, "Test", "Op", blocks[0])
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span { _, s := Span(ctx, "Test", "Op", WithBlock(blocks[0])); return s },
+		},
+		{
+			name: "block interface list",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithBlockListAttribute(ctx, "Test", "Op", []v1.Block{blocks[0], blocks[1]})
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span {
+				_, s := Span(ctx, "Test", "Op", WithBlocks([]v1.Block{blocks[0], blocks[1]}))
+				return s
+			},
+		},
+		{
+			name: "block list",
+			v1: func(ctx context.Context) trace.Span {
+				_, s := v1.SpanWithBlockListAttributeOf(ctx, "Test", "Op", blocks)
+				return s
+			},
+			v2: func(ctx context.Context) trace.Span { _, s := Span(ctx, "Test", "Op", WithBlocks(blocks)); return s },
+		},
+	}
+
+	modes := []struct {
+		name  string
+		setup func() context.Context
+	}{
+		{
+			name:  "default",
+			setup: func() context.Context { return context.Background() },
+		},
+		{
+			name: "short cids",
+			setup: func() context.Context {
+				v1.SetShortCids(true)
+				return context.Background()
+			},
+		},
+		{
+			name: "verbose",
+			setup: func() context.Context {
+				v1.SetVerboseTraces(true)
+				return v1.WithVerbose(context.Background())
+			},
+		},
+	}
+
+	for _, mode := range modes {
+		for _, tc := range testCases {
+			t.Run(mode.name+"/"+tc.name, func(t *testing.T) {
+				ctx := mode.setup()
+				defer v1.SetShortCids(false)
+				defer v1.SetVerboseTraces(false)
+
+				want := record(t, ctx, tc.v1)
+				got := record(t, ctx, tc.v2)
+				if got.name != want.name {
+					t.Errorf("name: got %q, want %q", got.name, want.name)
+				}
+				if !reflect.DeepEqual(got.startAttrs, want.startAttrs) {
+					t.Errorf("start attributes: got %v, want %v", got.startAttrs, want.startAttrs)
+				}
+				if !reflect.DeepEqual(got.attrs, want.attrs) {
+					t.Errorf("attributes: got %v, want %v", got.attrs, want.attrs)
+				}
+			})
+		}
+	}
+}
+
+// TestSpanModesChangeAttributes guards the comparison above against modes that have no effect
+func TestSpanModesChangeAttributes(t *testing.T) {
+	cs := testCids(t, 5)
+
+	blocks := []testBlock{{c: cs[0]}, {c: cs[1]}, {c: cs[2]}, {c: cs[3]}}
+
+	plain := record(t, context.Background(), func(ctx context.Context) trace.Span {
+		_, s := Span(ctx, "Test", "Op", WithCids(cs), WithCid(cs[0]), WithBlocks(blocks))
+		return s
+	})
+
+	v1.SetShortCids(true)
+	v1.SetVerboseTraces(true)
+	defer v1.SetShortCids(false)
+	defer v1.SetVerboseTraces(false)
+	changed := record(t, v1.WithVerbose(context.Background()), func(ctx context.Context) trace.Span {
+		_, s := Span(ctx, "Test", "Op", WithCids(cs), WithCid(cs[0]), WithBlocks(blocks))
+		return s
+	})
+
+	if plain.attrs["cids"] == changed.attrs["cids"] {
+		t.Errorf("verbose mode did not change the cid list: %q", plain.attrs["cids"])
+	}
+	if plain.attrs["blocks"] == changed.attrs["blocks"] {
+		t.Errorf("verbose mode did not change the block list: %q", plain.attrs["blocks"])
+	}
+	if _, ok := plain.attrs["cid.short"]; ok {
+		t.Error("short cid recorded while short cids are disabled")
+	}
+	if _, ok := changed.attrs["cid.short"]; !ok {
+		t.Error("short cid not recorded while short cids are enabled")
+	}
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// verboseKey is the baggage key used to switch a trace into verbose mode
+const verboseKey = "ipfs.verbose"
+
+var verboseAllowed int32
+
+// SetVerboseTraces controls whether the verbose flag carried in baggage is honoured. Verbose traces
+// record extra attributes that are expensive to produce, such as full cid lists and want-lists, so
+// that support can capture maximum detail for a single reproduction without raising the cost of every
+// trace. Since baggage may be set by remote clients the flag is ignored unless verbose traces have
+// been allowed. They are disallowed by default.
+func SetVerboseTraces(allowed bool) {
+	var v int32
+	if allowed {
+		v = 1
+	}
+	atomic.StoreInt32(&verboseAllowed, v)
+}
+
+// WithVerbose returns a context carrying the verbose flag in its baggage, switching the trace into
+// verbose mode in this process and in any process the baggage is propagated to
+func WithVerbose(ctx context.Context) context.Context {
+	if m, err := baggage.NewMember(verboseKey, "1"); err == nil {
+		if b, err := baggage.FromContext(ctx).SetMember(m); err == nil {
+			ctx = baggage.ContextWithBaggage(ctx, b)
+		}
+	}
+	return ctx
+}
+
+// Verbose reports whether the context belongs to a verbose trace and verbose traces are allowed
+func Verbose(ctx context.Context) bool {
+	if atomic.LoadInt32(&verboseAllowed) == 0 {
+		return false
+	}
+	return baggage.FromContext(ctx).Member(verboseKey).Value() == "1"
+}
+
+// VerboseCidListAttribute creates a span attribute with a standard name for representing a list of
+// CIDs. Every cid is included when the context belongs to a verbose trace, otherwise the list is
+// summarized as by CidListAttribute.
+func VerboseCidListAttribute(ctx context.Context, cs []cid.Cid) attribute.KeyValue {
+	if Verbose(ctx) {
+		return attribute.String("cids", cidListString(cs, len(cs)))
+	}
+	return CidListAttribute(cs)
+}
+
+// VerboseBlockListAttribute creates a span attribute with a standard name for representing a list of
+// blocks. Every block is included when the context belongs to a verbose trace, otherwise the list is
+// summarized as by BlockListAttribute. Use VerboseBlockListAttributeOf for a slice of a specific block
+// type.
+func VerboseBlockListAttribute(ctx context.Context, bs []Block) attribute.KeyValue {
+	return VerboseBlockListAttributeOf(ctx, bs)
+}
+
+// VerboseBlockListAttributeOf is like VerboseBlockListAttribute but accepts a slice of any block type
+func VerboseBlockListAttributeOf[B Block](ctx context.Context, bs []B) attribute.KeyValue {
+	if Verbose(ctx) {
+		return attribute.String("blocks", blockListString(bs, len(bs)))
+	}
+	return BlockListAttributeOf(bs)
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+func TestSpanWithBlockListAttributeVerbose(t *testing.T) {
+	rec := withRecorder(t)
+
+	var bs []Block
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		bs = append(bs, testBlock{c: cid.NewCidV1(cid.Raw, mustMultihash(t, s))})
+	}
+
+	SetVerboseTraces(true)
+	defer SetVerboseTraces(false)
+
+	_, span := SpanWithBlockListAttribute(context.Background(), "Test", "Plain", bs)
+	span.End()
+	_, span = SpanWithBlockListAttribute(WithVerbose(context.Background()), "Test", "Verbose", bs)
+	span.End()
+
+	ended := rec.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d ended spans, want 2", len(ended))
+	}
+
+	plain := attributeMap(ended[0].Attributes())
+	if !strings.HasSuffix(plain["blocks"], " and 2 more") {
+		t.Errorf("plain trace: got blocks %q, want a summary of the list", plain["blocks"])
+	}
+
+	verbose := attributeMap(ended[1].Attributes())
+	if got, want := verbose["blocks"], blockListString(bs, len(bs)); got != want {
+		t.Errorf("verbose trace: got blocks %q, want %q", got, want)
+	}
+	if strings.Contains(verbose["blocks"], "more") {
+		t.Errorf("verbose trace: blocks %q were summarized", verbose["blocks"])
+	}
+	if got := verbose["blocks.count"]; got != "5" {
+		t.Errorf("verbose trace: got blocks.count %q, want 5", got)
+	}
+}